@@ -0,0 +1,36 @@
+package linode
+
+import (
+	"context"
+
+	"github.com/linode/linodego"
+)
+
+// DomainAPI is the part of the Linode API the provider uses. It is
+// implemented by *linodego.Client, and by linodetest.Fake for tests of code
+// using the provider.
+type DomainAPI interface {
+	ListDomains(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error)
+	GetDomain(ctx context.Context, domainID int) (*linodego.Domain, error)
+	CreateDomain(ctx context.Context, opts linodego.DomainCreateOptions) (*linodego.Domain, error)
+	UpdateDomain(ctx context.Context, domainID int, opts linodego.DomainUpdateOptions) (*linodego.Domain, error)
+	DeleteDomain(ctx context.Context, domainID int) error
+	GetDomainZoneFile(ctx context.Context, domainID int) (*linodego.DomainZoneFile, error)
+	ListDomainRecords(ctx context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error)
+	CreateDomainRecord(ctx context.Context, domainID int, opts linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error)
+	UpdateDomainRecord(ctx context.Context, domainID, recordID int, opts linodego.DomainRecordUpdateOptions) (*linodego.DomainRecord, error)
+	DeleteDomainRecord(ctx context.Context, domainID, recordID int) error
+	ListEvents(ctx context.Context, opts *linodego.ListOptions) ([]linodego.Event, error)
+}
+
+var _ DomainAPI = (*linodego.Client)(nil)
+
+// ProfileAPI is the part of the Linode API that reports the permissions of
+// the API token's user. A DomainAPI that also implements it, as
+// *linodego.Client and linodetest.Fake do, supports ZoneWritable.
+type ProfileAPI interface {
+	GetProfile(ctx context.Context) (*linodego.Profile, error)
+	GrantsList(ctx context.Context) (*linodego.GrantsListResponse, error)
+}
+
+var _ ProfileAPI = (*linodego.Client)(nil)
@@ -0,0 +1,100 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+	"github.com/miekg/dns"
+)
+
+// defaultAXFRServer is the Linode nameserver zones are transferred from.
+const defaultAXFRServer = "ns1.linode.com:53"
+
+// transferRecords reads the records of the zone with a DNS zone transfer from
+// Linode's nameservers. It requires the IP address of this host to be in the
+// domain's axfr_ips. The returned records carry no Linode record IDs.
+func (p *Provider) transferRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	server := p.AXFRServer
+	if server == "" {
+		server = defaultAXFRServer
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(zone))
+	transfer := new(dns.Transfer)
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout := time.Until(deadline)
+		transfer.DialTimeout = timeout
+		transfer.ReadTimeout = timeout
+	}
+	envelopes, err := transfer.In(msg, server)
+	if err != nil {
+		return nil, fmt.Errorf("could not transfer zone %s: %v", zone, err)
+	}
+	var records []libdns.Record
+	for envelope := range envelopes {
+		if envelope.Error != nil {
+			return nil, fmt.Errorf("could not transfer zone %s: %v", zone, envelope.Error)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for _, rr := range envelope.RR {
+			if record, ok := convertFromDNSRR(zone, rr); ok {
+				records = append(records, record)
+			}
+		}
+	}
+	return records, nil
+}
+
+// convertFromDNSRR converts a transferred resource record. It skips the SOA
+// record and the apex NS records of Linode's own nameservers, which the Linode
+// API does not return as records either.
+func convertFromDNSRR(zone string, rr dns.RR) (libdns.Record, bool) {
+	header := rr.Header()
+	name := libdns.RelativeName(header.Name, zone)
+	switch rr := rr.(type) {
+	case *dns.SOA:
+		return nil, false
+	case *dns.NS:
+		if name == "@" && strings.HasSuffix(strings.ToLower(dns.Fqdn(rr.Ns)), ".linode.com.") {
+			return nil, false
+		}
+	}
+	generic := rrFromDNS(rr)
+	generic.Name = name
+	record, err := parseRecord(generic)
+	if err != nil {
+		return generic, true
+	}
+	return record, true
+}
+
+// GetZoneTransferIPs returns the IP addresses allowed to transfer the zone
+// from Linode's nameservers.
+func (p *Provider) GetZoneTransferIPs(ctx context.Context, zone string) ([]netip.Addr, error) {
+	domain, err := p.zoneDomain(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return parseIPs(domain.AXfrIPs)
+}
+
+// SetZoneTransferIPs replaces the IP addresses allowed to transfer the zone,
+// such as those of secondary nameservers of its own or of the host using
+// UseAXFR. An empty list disallows zone transfers.
+func (p *Provider) SetZoneTransferIPs(ctx context.Context, zone string, ips []netip.Addr) error {
+	_, err := p.updateZoneDomain(ctx, zone, func(opts *linodego.DomainUpdateOptions) {
+		opts.AXfrIPs = ipStrings(ips)
+	})
+	return err
+}
@@ -0,0 +1,462 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+func (p *Provider) appendRecords(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	if err := validateRecords(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkNames(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkScope(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkWritable(zone); err != nil {
+		return nil, err
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	var duplicates []libdns.Record
+	if p.SkipExisting {
+		existing, err := p.listDomainRecords(ctx, zone, domainID)
+		if err != nil {
+			return nil, err
+		}
+		duplicates = p.findDuplicates(zone, existing, records)
+	}
+	if isDryRun(ctx) {
+		results := plannedResults(records, OutcomeCreated)
+		for i, duplicate := range duplicates {
+			if duplicate != nil {
+				results[i] = RecordResult{Record: duplicate, Outcome: OutcomeNoOp}
+			}
+		}
+		return results, nil
+	}
+	defer p.invalidateRecords(zone)
+	results := newRecordResults(records)
+	defer p.notifyWebhook(zone, "append", results)
+	var batchErr BatchError
+	err = p.forEach(ctx, len(records), func(i int) error {
+		record := records[i]
+		if duplicates != nil && duplicates[i] != nil {
+			results[i] = RecordResult{Record: duplicates[i], Outcome: OutcomeNoOp}
+			return nil
+		}
+		addedRecord, err := p.createDomainRecord(ctx, zone, domainID, record)
+		if err != nil && p.IdempotentAppend && isDuplicateError(err) {
+			if existing, findErr := p.findMatchingRecords(ctx, zone, domainID, record); findErr == nil && len(existing) > 0 {
+				results[i] = RecordResult{Record: existing[0], Outcome: OutcomeNoOp}
+				return nil
+			}
+		}
+		if err != nil {
+			results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}
+			return p.handleRecordError(&batchErr, i, record, err)
+		}
+		results[i] = RecordResult{Record: addedRecord, Outcome: OutcomeCreated}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, batchErr.errOrNil()
+}
+
+// findDuplicates returns, for every record, an existing record with the same
+// name, type, data and TTL, or nil. A record without a TTL matches any TTL.
+// Every existing record is matched at most once.
+func (p *Provider) findDuplicates(zone string, existing, records []libdns.Record) []libdns.Record {
+	duplicates := make([]libdns.Record, len(records))
+	claimed := make(map[int]bool)
+	for i, record := range records {
+		key := newRecordKey(zone, record)
+		ttl := p.ttlDuration(zone, record.RR().TTL)
+		for _, candidate := range existing {
+			id, ok := recordID(candidate)
+			if !ok || claimed[id] || newRecordKey(zone, candidate) != key {
+				continue
+			}
+			if ttl == 0 || roundTTL(ttl) == candidate.RR().TTL {
+				claimed[id] = true
+				duplicates[i] = candidate
+				break
+			}
+		}
+	}
+	return duplicates
+}
+
+func (p *Provider) setRecords(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	return p.reconcile(ctx, zone, records, "set", nil)
+}
+
+// reconcile replaces the RRsets of the records in the zone, and with a prune
+// function also deletes the existing records outside of those RRsets it
+// selects. The operation names the call in confirmations and webhooks.
+func (p *Provider) reconcile(ctx context.Context, zone string, records []libdns.Record, operation string, prune func(libdns.Record) bool) ([]RecordResult, error) {
+	if err := validateRecords(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkNames(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkScope(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkWritable(zone); err != nil {
+		return nil, err
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	existing, err := p.listDomainRecords(ctx, zone, domainID)
+	if err != nil {
+		return nil, err
+	}
+	plan := p.planSet(zone, existing, records)
+	if prune != nil {
+		plan.deletions = append(plan.deletions, p.planPrune(zone, existing, records, prune)...)
+	}
+	if err := p.checkDeletions(ctx, len(plan.deletions), len(existing)); err != nil {
+		return nil, err
+	}
+	if isDryRun(ctx) {
+		return plan.results(records), nil
+	}
+	changes := DestructiveChanges{Zone: zone, Operation: operation, Deletes: plan.deletions}
+	for i, record := range records {
+		if plan.matches[i] != nil && !plan.unchanged[i] && normalizeName(zone, record.RR().Name) == "@" {
+			changes.ApexChanges = append(changes.ApexChanges, record)
+		}
+	}
+	if err := p.confirm(ctx, changes); err != nil {
+		return nil, err
+	}
+	defer p.invalidateRecords(zone)
+	results := newRecordResults(append(records[:len(records):len(records)], plan.deletions...))
+	defer p.notifyWebhook(zone, operation, results)
+	var batchErr BatchError
+	err = p.forEach(ctx, len(records), func(i int) error {
+		record := records[i]
+		var updatedRecord libdns.Record
+		var err error
+		outcome := OutcomeUpdated
+		switch match := plan.matches[i]; {
+		case match == nil:
+			updatedRecord, err = p.createDomainRecord(ctx, zone, domainID, record)
+			outcome = OutcomeCreated
+		case plan.unchanged[i]:
+			updatedRecord, outcome = match, OutcomeNoOp
+		default:
+			updatedRecord, err = p.updateDomainRecord(ctx, zone, domainID, record, match)
+			if isConflictError(err) {
+				// The record was changed or removed by someone else since it was read.
+				updatedRecord, outcome, err = p.setAfterConflict(ctx, zone, domainID, record)
+			}
+		}
+		if err != nil {
+			results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}
+			return p.handleRecordError(&batchErr, i, record, err)
+		}
+		results[i] = RecordResult{Record: updatedRecord, Outcome: outcome}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	err = p.forEach(ctx, len(plan.deletions), func(j int) error {
+		record, i := plan.deletions[j], len(records)+j
+		err := p.deleteDomainRecord(ctx, zone, domainID, record)
+		if err != nil && errorStatus(err) == http.StatusNotFound {
+			err = nil
+		}
+		if err != nil {
+			results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}
+			return p.handleRecordError(&batchErr, i, record, err)
+		}
+		results[i] = RecordResult{Record: record, Outcome: OutcomeDeleted}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, batchErr.errOrNil()
+}
+
+// setPlan holds the writes that make the RRsets of a zone match the records
+// passed to SetRecords.
+type setPlan struct {
+	// matches holds, for every desired record, the existing record to update,
+	// or nil if the record must be created.
+	matches []libdns.Record
+	// unchanged reports the desired records whose match already equals them.
+	unchanged []bool
+	// deletions are the existing records of the RRsets that are not reused.
+	deletions []libdns.Record
+}
+
+// results returns the results of the plan's writes for a dry run.
+func (plan setPlan) results(desired []libdns.Record) []RecordResult {
+	results := make([]RecordResult, 0, len(desired)+len(plan.deletions))
+	for i, record := range desired {
+		switch {
+		case plan.matches[i] == nil:
+			results = append(results, RecordResult{Record: record, Outcome: OutcomeCreated})
+		case plan.unchanged[i]:
+			results = append(results, RecordResult{Record: plan.matches[i], Outcome: OutcomeNoOp})
+		default:
+			results = append(results, RecordResult{Record: record, Outcome: OutcomeUpdated})
+		}
+	}
+	return append(results, plannedResults(plan.deletions, OutcomeDeleted)...)
+}
+
+// plannedResults returns a result with the outcome for every record, for a
+// dry run.
+func plannedResults(records []libdns.Record, outcome Outcome) []RecordResult {
+	results := make([]RecordResult, len(records))
+	for i, record := range records {
+		results[i] = RecordResult{Record: record, Outcome: outcome}
+	}
+	return results
+}
+
+// planSet computes the fewest writes that replace the RRsets of the desired
+// records in the zone. Existing records with the same data are kept, the
+// remaining ones are updated in place before new records are created, and
+// the leftovers are deleted. A desired record without a TTL keeps the TTL
+// of the record it matches. Records without a Linode ID are ignored.
+func (p *Provider) planSet(zone string, existing, desired []libdns.Record) setPlan {
+	plan := setPlan{
+		matches:   make([]libdns.Record, len(desired)),
+		unchanged: make([]bool, len(desired)),
+	}
+	buckets := make(map[rrsetKey][]libdns.Record, len(desired))
+	for _, record := range desired {
+		buckets[newRRSetKey(zone, record)] = nil
+	}
+	for _, record := range existing {
+		key := newRRSetKey(zone, record)
+		if bucket, ok := buckets[key]; ok {
+			if _, ok := recordID(record); ok {
+				buckets[key] = append(bucket, record)
+			}
+		}
+	}
+	claimed := make(map[int]bool)
+	claim := func(i int, match libdns.Record) {
+		id, _ := recordID(match)
+		claimed[id] = true
+		plan.matches[i] = match
+	}
+	// Keep the records whose data is already in the zone.
+	for i, record := range desired {
+		key := newRecordKey(zone, record)
+		for _, candidate := range buckets[newRRSetKey(zone, record)] {
+			if id, _ := recordID(candidate); !claimed[id] && newRecordKey(zone, candidate) == key {
+				claim(i, candidate)
+				ttl := p.ttlDuration(zone, record.RR().TTL)
+				plan.unchanged[i] = ttl == 0 || roundTTL(ttl) == candidate.RR().TTL
+				break
+			}
+		}
+	}
+	// Update the remaining records of the RRsets in place, preferring the
+	// record a desired record was read from.
+	for i, record := range desired {
+		if plan.matches[i] != nil {
+			continue
+		}
+		wantID, hasID := recordID(record)
+		var match libdns.Record
+		for _, candidate := range buckets[newRRSetKey(zone, record)] {
+			id, _ := recordID(candidate)
+			if claimed[id] {
+				continue
+			}
+			if match == nil || (hasID && id == wantID) {
+				match = candidate
+			}
+		}
+		if match != nil {
+			claim(i, match)
+		}
+	}
+	for _, record := range existing {
+		if _, ok := buckets[newRRSetKey(zone, record)]; !ok {
+			continue
+		}
+		if id, ok := recordID(record); ok && !claimed[id] {
+			plan.deletions = append(plan.deletions, record)
+		}
+	}
+	return plan
+}
+
+func (p *Provider) deleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	if err := p.checkScope(zone, records); err != nil {
+		return nil, err
+	}
+	if err := p.checkWritable(zone); err != nil {
+		return nil, err
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	var existing []libdns.Record
+	if (p.MaxDeletionPercent > 0 && !isForced(ctx)) || hasRecordWithoutID(records) {
+		existing, err = p.listDomainRecords(ctx, zone, domainID)
+		if err != nil {
+			return nil, err
+		}
+	}
+	deletions := planDeletions(zone, existing, records)
+	var deletes []libdns.Record
+	for _, record := range deletions {
+		if _, ok := recordID(record); ok {
+			deletes = append(deletes, record)
+		}
+	}
+	if err := p.checkDeletions(ctx, len(deletes), len(existing)); err != nil {
+		return nil, err
+	}
+	if isDryRun(ctx) {
+		results := plannedResults(deletions, OutcomeDeleted)
+		for i, record := range deletions {
+			if _, ok := recordID(record); !ok && !p.IdempotentDelete {
+				results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: recordNotFoundError(errNoMatchingRecord)}
+			}
+		}
+		return results, nil
+	}
+	if err := p.confirm(ctx, DestructiveChanges{Zone: zone, Operation: "delete", Deletes: deletes}); err != nil {
+		return nil, err
+	}
+	defer p.invalidateRecords(zone)
+	results := newRecordResults(deletions)
+	defer p.notifyWebhook(zone, "delete", results)
+	var batchErr BatchError
+	err = p.forEach(ctx, len(deletions), func(i int) error {
+		record := deletions[i]
+		err := error(errNoMatchingRecord)
+		if _, ok := recordID(record); ok {
+			err = p.deleteDomainRecord(ctx, zone, domainID, record)
+		}
+		err = recordNotFoundError(err)
+		if err != nil && p.IdempotentDelete && errorStatus(err) == http.StatusNotFound {
+			err = nil
+		}
+		if err != nil {
+			results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}
+			return p.handleRecordError(&batchErr, i, record, err)
+		}
+		results[i] = RecordResult{Record: record, Outcome: OutcomeDeleted}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, batchErr.errOrNil()
+}
+
+// errNoMatchingRecord is the error of deleting a record without a Linode ID
+// that matches no record of the zone.
+var errNoMatchingRecord = &linodego.Error{Code: http.StatusNotFound, Message: "no matching record found"}
+
+// hasRecordWithoutID reports whether any of the records has no Linode ID.
+func hasRecordWithoutID(records []libdns.Record) bool {
+	for _, record := range records {
+		if _, ok := recordID(record); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// planDeletions expands the records to delete into the existing records they
+// delete, so that limits and confirmations see what is actually removed. A
+// record with a Linode ID is deleted by its ID. A record without one is
+// replaced by the first existing record it matches, see matchesDeletion, and
+// its further matches are appended after the records; if it matches none, it
+// is kept, and its deletion fails as not found. Every existing record is
+// deleted at most once.
+func planDeletions(zone string, existing, records []libdns.Record) []libdns.Record {
+	deletions := append([]libdns.Record(nil), records...)
+	claimed := make(map[int]bool)
+	for _, record := range records {
+		if id, ok := recordID(record); ok {
+			claimed[id] = true
+		}
+	}
+	for i, record := range records {
+		if _, ok := recordID(record); ok {
+			continue
+		}
+		matched := false
+		for _, candidate := range existing {
+			id, ok := recordID(candidate)
+			if !ok || claimed[id] || !matchesDeletion(zone, record, candidate) {
+				continue
+			}
+			claimed[id] = true
+			if !matched {
+				deletions[i], matched = candidate, true
+				continue
+			}
+			deletions = append(deletions, candidate)
+		}
+	}
+	return deletions
+}
+
+// handleRecordError returns err to abort the batch, or collects it in batchErr
+// and returns nil when the provider is configured to continue on errors.
+func (p *Provider) handleRecordError(batchErr *BatchError, index int, record libdns.Record, err error) error {
+	if !p.ContinueOnError {
+		return err
+	}
+	batchErr.mutex.Lock()
+	defer batchErr.mutex.Unlock()
+	batchErr.Errors = append(batchErr.Errors, &RecordError{Index: index, Record: record, Err: err})
+	return nil
+}
@@ -0,0 +1,259 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/linode/linodego"
+)
+
+// countingAPI counts the record listings made through it.
+type countingAPI struct {
+	linode.DomainAPI
+	listings int
+}
+
+func (api *countingAPI) ListDomainRecords(ctx context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error) {
+	api.listings++
+	return api.DomainAPI.ListDomainRecords(ctx, domainID, opts)
+}
+
+func TestDeleteRecordsWildcardLimits(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	mustAppend(t, provider,
+		mustParse(t, "@", "MX", "10 mail.example.com."),
+		mustParse(t, "@", "CAA", `0 issue "letsencrypt.org"`),
+		mustParse(t, "www", "A", "192.0.2.1"),
+	)
+	provider.MaxDeletionsPerCall = 1
+	wildcard := []libdns.Record{libdns.RR{Name: "@"}}
+	_, err := provider.DeleteRecords(context.Background(), testZone, wildcard)
+	if !errors.Is(err, linode.ErrTooManyDeletions) {
+		t.Fatalf("DeleteRecords error = %v, want ErrTooManyDeletions", err)
+	}
+	if records := fake.Records(domainID); len(records) != 3 {
+		t.Fatalf("zone has %d records, want 3", len(records))
+	}
+}
+
+func TestDeleteRecordsWildcardConfirm(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	api := &countingAPI{DomainAPI: fake}
+	provider.API = api
+	mustAppend(t, provider,
+		mustParse(t, "@", "MX", "10 mail.example.com."),
+		mustParse(t, "@", "CAA", `0 issue "letsencrypt.org"`),
+		mustParse(t, "www", "A", "192.0.2.1"),
+	)
+	api.listings = 0
+	var confirmed []libdns.Record
+	provider.Confirm = func(_ context.Context, changes linode.DestructiveChanges) bool {
+		confirmed = changes.Deletes
+		return true
+	}
+	deleted, err := provider.DeleteRecords(context.Background(), testZone, []libdns.Record{
+		libdns.RR{Name: "@"},
+		libdns.RR{Name: "www", Type: "A"},
+	})
+	if err != nil {
+		t.Fatalf("DeleteRecords: %v", err)
+	}
+	if len(confirmed) != 3 {
+		t.Errorf("Confirm saw %d deletions, want 3", len(confirmed))
+	}
+	if len(deleted) != 3 {
+		t.Errorf("DeleteRecords returned %d records, want 3", len(deleted))
+	}
+	if api.listings != 1 {
+		t.Errorf("zone was listed %d times, want once", api.listings)
+	}
+	if records := fake.Records(domainID); len(records) != 0 {
+		t.Fatalf("zone has %d records, want none", len(records))
+	}
+}
+
+func TestDeleteRecordsNotFound(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	missing := []libdns.Record{libdns.RR{Name: "www", Type: "A"}}
+	if _, err := provider.DeleteRecords(context.Background(), testZone, missing); !errors.Is(err, linode.ErrRecordNotFound) {
+		t.Fatalf("DeleteRecords error = %v, want ErrRecordNotFound", err)
+	}
+	provider.IdempotentDelete = true
+	if _, err := provider.DeleteRecords(context.Background(), testZone, missing); err != nil {
+		t.Fatalf("DeleteRecords with IdempotentDelete: %v", err)
+	}
+}
+
+// rrsetData returns the data of the records of the RRset, sorted.
+func rrsetData(records []libdns.Record, name, recordType string) []string {
+	var data []string
+	for _, record := range records {
+		if rr := record.RR(); rr.Name == name && rr.Type == recordType {
+			data = append(data, rr.Data)
+		}
+	}
+	sort.Strings(data)
+	return data
+}
+
+func TestSetRecordsReplacesRRSets(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []libdns.Record
+		set      []libdns.Record
+		// want is the data of the www RRset of recordType after the call.
+		recordType string
+		want       []string
+		outcomes   map[linode.Outcome]int
+	}{
+		{
+			name: "multi-value A shrinks",
+			existing: []libdns.Record{
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.2"},
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.3"},
+			},
+			set:        []libdns.Record{libdns.RR{Name: "www", Type: "A", Data: "192.0.2.2"}},
+			recordType: "A",
+			want:       []string{"192.0.2.2"},
+			outcomes:   map[linode.Outcome]int{linode.OutcomeNoOp: 1, linode.OutcomeDeleted: 2},
+		},
+		{
+			name: "multi-value A grows and changes",
+			existing: []libdns.Record{
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+			},
+			set: []libdns.Record{
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.4"},
+				libdns.RR{Name: "www", Type: "A", Data: "192.0.2.5"},
+			},
+			recordType: "A",
+			want:       []string{"192.0.2.4", "192.0.2.5"},
+			outcomes:   map[linode.Outcome]int{linode.OutcomeUpdated: 1, linode.OutcomeCreated: 1},
+		},
+		{
+			name: "multi-value TXT",
+			existing: []libdns.Record{
+				libdns.RR{Name: "www", Type: "TXT", Data: "one"},
+				libdns.RR{Name: "www", Type: "TXT", Data: "two"},
+			},
+			set: []libdns.Record{
+				libdns.RR{Name: "www", Type: "TXT", Data: "two"},
+				libdns.RR{Name: "www", Type: "TXT", Data: "three"},
+				libdns.RR{Name: "www", Type: "TXT", Data: "four"},
+			},
+			recordType: "TXT",
+			want:       []string{"four", "three", "two"},
+			outcomes:   map[linode.Outcome]int{linode.OutcomeNoOp: 1, linode.OutcomeUpdated: 1, linode.OutcomeCreated: 1},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, _, _ := newTestProvider(t)
+			other := libdns.RR{Name: "mail", Type: "A", Data: "192.0.2.9"}
+			mustAppend(t, provider, append(test.existing, other)...)
+
+			results, err := provider.SetRecordsWithResults(context.Background(), testZone, test.set)
+			if err != nil {
+				t.Fatalf("SetRecords: %v", err)
+			}
+			outcomes := make(map[linode.Outcome]int)
+			for _, result := range results {
+				outcomes[result.Outcome]++
+			}
+			if !reflect.DeepEqual(outcomes, test.outcomes) {
+				t.Errorf("outcomes = %v, want %v", outcomes, test.outcomes)
+			}
+			records := mustGet(t, provider)
+			if got := rrsetData(records, "www", test.recordType); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("www %s = %v, want %v", test.recordType, got, test.want)
+			}
+			if got := rrsetData(records, "mail", "A"); !reflect.DeepEqual(got, []string{other.Data}) {
+				t.Errorf("mail A = %v, want it untouched", got)
+			}
+		})
+	}
+}
+
+func TestAppendRecordsSkipExisting(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	provider.SkipExisting = true
+	existing := mustAppend(t, provider,
+		libdns.RR{Name: "www", Type: "A", TTL: time.Hour, Data: "192.0.2.1"},
+		libdns.RR{Name: "_acme-challenge", Type: "TXT", TTL: time.Minute, Data: "token"},
+	)
+
+	results, err := provider.AppendRecordsWithResults(context.Background(), testZone, []libdns.Record{
+		// Identical, so skipped.
+		libdns.RR{Name: "www", Type: "A", TTL: time.Hour, Data: "192.0.2.1"},
+		// Without a TTL, matching any TTL.
+		libdns.RR{Name: "_acme-challenge", Type: "TXT", Data: "token"},
+		// Different data, so created.
+		libdns.RR{Name: "www", Type: "A", TTL: time.Hour, Data: "192.0.2.2"},
+	})
+	if err != nil {
+		t.Fatalf("AppendRecords: %v", err)
+	}
+	want := []linode.Outcome{linode.OutcomeNoOp, linode.OutcomeNoOp, linode.OutcomeCreated}
+	for i, result := range results {
+		if result.Outcome != want[i] {
+			t.Errorf("result %d = %s, want %s", i, result.Outcome, want[i])
+		}
+	}
+	for i := range existing {
+		if !reflect.DeepEqual(results[i].Record, existing[i]) {
+			t.Errorf("result %d = %+v, want the existing record %+v", i, results[i].Record, existing[i])
+		}
+	}
+	if records := fake.Records(domainID); len(records) != 3 {
+		t.Fatalf("zone has %d records, want 3", len(records))
+	}
+}
+
+func TestAppendRecordsSkipExistingOnce(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	provider.SkipExisting = true
+	provider.ContinueOnError = true
+	mustAppend(t, provider, libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"})
+
+	// Only one of the two identical records is matched by the existing one;
+	// the other is created and rejected by Linode's duplicate check.
+	record := libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"}
+	results, err := provider.AppendRecordsWithResults(context.Background(), testZone, []libdns.Record{record, record})
+	if err == nil {
+		t.Fatal("expected the duplicate to fail")
+	}
+	if results[0].Outcome != linode.OutcomeNoOp || results[1].Outcome != linode.OutcomeFailed {
+		t.Fatalf("outcomes = %s, %s; want no-op, failed", results[0].Outcome, results[1].Outcome)
+	}
+	if records := fake.Records(domainID); len(records) != 1 {
+		t.Fatalf("zone has %d records, want 1", len(records))
+	}
+}
+
+func TestAppendRecordsSkipExistingDryRun(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	provider.SkipExisting = true
+	mustAppend(t, provider, libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"})
+
+	ctx := linode.WithDryRun(context.Background())
+	results, err := provider.AppendRecordsWithResults(ctx, testZone, []libdns.Record{
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.1"},
+		libdns.RR{Name: "www", Type: "A", Data: "192.0.2.2"},
+	})
+	if err != nil {
+		t.Fatalf("AppendRecords: %v", err)
+	}
+	if results[0].Outcome != linode.OutcomeNoOp || results[1].Outcome != linode.OutcomeCreated {
+		t.Fatalf("outcomes = %s, %s; want no-op, created", results[0].Outcome, results[1].Outcome)
+	}
+	if records := fake.Records(domainID); len(records) != 1 {
+		t.Fatalf("zone has %d records, want 1", len(records))
+	}
+}
@@ -0,0 +1,179 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// recordCacheEntry holds the records of a zone as last fetched from Linode.
+type recordCacheEntry struct {
+	records    []libdns.Record
+	fetched    time.Time
+	refreshing bool
+	// stale is set when the records were served because Linode was unreachable.
+	stale bool
+}
+
+// zoneKey normalizes a zone name for use as a cache key.
+func zoneKey(zone string) string {
+	return strings.ToLower(strings.TrimSuffix(zone, "."))
+}
+
+// cachedRecords returns the cached records of the zone, if the record cache is
+// enabled and holds a usable entry. An entry that expired less than
+// StaleWhileRevalidate ago is still returned, and refreshed in the background.
+// The caller must hold p.mutex.
+func (p *Provider) cachedRecords(zone string) ([]libdns.Record, bool) {
+	if p.RecordCacheTTL <= 0 {
+		return nil, false
+	}
+	entry, ok := p.recordCache[zoneKey(zone)]
+	var age time.Duration
+	if ok {
+		age = time.Since(entry.fetched)
+	}
+	if !ok || age > p.RecordCacheTTL+p.StaleWhileRevalidate {
+		p.stats.update(func(s *Stats) { s.CacheMisses++ })
+		return nil, false
+	}
+	p.stats.update(func(s *Stats) { s.CacheHits++ })
+	if age > p.RecordCacheTTL && !entry.refreshing {
+		entry.refreshing = true
+		go p.refreshRecords(zone, entry)
+	}
+	return append([]libdns.Record(nil), entry.records...), true
+}
+
+// refreshTimeout limits the time a background refresh of cached records may
+// take.
+const refreshTimeout = time.Minute
+
+// refreshRecords fetches the records of the zone in the background and
+// replaces the stale cache entry. The records are fetched without holding
+// p.mutex, so that GetRecords keeps serving the stale entry meanwhile.
+func (p *Provider) refreshRecords(zone string, entry *recordCacheEntry) {
+	ctx, cancel := context.WithTimeout(withZone(context.Background(), zone), refreshTimeout)
+	defer cancel()
+	p.mutex.Lock()
+	domainID, cached := p.cachedDomainID(zone)
+	p.mutex.Unlock()
+	var records []libdns.Record
+	var err error
+	if !cached {
+		domainID, err = p.lookupDomainID(ctx, zone)
+	}
+	if err == nil {
+		records, err = p.readDomainRecords(ctx, zone, domainID)
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	entry.refreshing = false
+	switch {
+	case errors.Is(err, ErrZoneNotFound):
+		p.uncacheDomainID(zone)
+	case err != nil:
+	case p.recordCache[zoneKey(zone)] != entry:
+		// The entry was invalidated or replaced in the meantime.
+	default:
+		if !cached {
+			p.cacheDomainID(zone, domainID)
+		}
+		p.cacheRecords(zone, records)
+	}
+}
+
+// cacheRecords stores the records of the zone if the record cache or
+// ServeStaleOnOutage is enabled. The caller must hold p.mutex.
+func (p *Provider) cacheRecords(zone string, records []libdns.Record) {
+	if p.RecordCacheTTL <= 0 && !p.ServeStaleOnOutage {
+		return
+	}
+	if p.recordCache == nil {
+		p.recordCache = make(map[string]*recordCacheEntry)
+	}
+	p.recordCache[zoneKey(zone)] = &recordCacheEntry{
+		records: append([]libdns.Record(nil), records...),
+		fetched: time.Now(),
+	}
+}
+
+// staleRecords returns the records last fetched for the zone, however old,
+// if ServeStaleOnOutage is enabled and err means that Linode is unreachable.
+// The caller must hold p.mutex.
+func (p *Provider) staleRecords(ctx context.Context, zone string, err error) ([]libdns.Record, bool) {
+	if !p.ServeStaleOnOutage || ctx.Err() != nil || !isOutageError(err) {
+		return nil, false
+	}
+	entry, ok := p.recordCache[zoneKey(zone)]
+	if !ok {
+		return nil, false
+	}
+	entry.stale = true
+	return append([]libdns.Record(nil), entry.records...), true
+}
+
+// RecordsStale reports whether the records GetRecords last returned for the
+// zone were served from the cache because Linode was unreachable, see
+// ServeStaleOnOutage, and when those records were fetched.
+func (p *Provider) RecordsStale(zone string) (fetched time.Time, stale bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	entry, ok := p.recordCache[zoneKey(zone)]
+	if !ok || !entry.stale {
+		return time.Time{}, false
+	}
+	return entry.fetched, true
+}
+
+// invalidateRecords drops the cached records of the zone.
+// The caller must hold p.mutex.
+func (p *Provider) invalidateRecords(zone string) {
+	delete(p.recordCache, zoneKey(zone))
+}
+
+// cachesDomainIDs reports whether the IDs of domains are cached. They are
+// cached when DomainIDCacheTTL is set, along with the records, or when a cache
+// file is used.
+func (p *Provider) cachesDomainIDs() bool {
+	return p.DomainIDCacheTTL > 0 || p.RecordCacheTTL > 0 || p.CacheFile != ""
+}
+
+// cachedDomainID returns the configured or cached ID of the zone's domain.
+// The caller must hold p.mutex.
+func (p *Provider) cachedDomainID(zone string) (int, bool) {
+	if id, ok := p.configuredDomainID(zone); ok {
+		return id, true
+	}
+	key := zoneKey(zone)
+	id, ok := p.domainIDs[key]
+	if ok && p.DomainIDCacheTTL > 0 && time.Since(p.domainIDsCached[key]) > p.DomainIDCacheTTL {
+		p.uncacheDomainID(zone)
+		return 0, false
+	}
+	return id, ok
+}
+
+// cacheDomainID stores the ID of the zone's domain if domain IDs are cached.
+// The caller must hold p.mutex.
+func (p *Provider) cacheDomainID(zone string, id int) {
+	if !p.cachesDomainIDs() {
+		return
+	}
+	if p.domainIDs == nil {
+		p.domainIDs = make(map[string]int)
+		p.domainIDsCached = make(map[string]time.Time)
+	}
+	p.domainIDs[zoneKey(zone)] = id
+	p.domainIDsCached[zoneKey(zone)] = time.Now()
+}
+
+// uncacheDomainID removes the cached ID of the zone's domain, such as after
+// the domain was not found. The caller must hold p.mutex.
+func (p *Provider) uncacheDomainID(zone string) {
+	delete(p.domainIDs, zoneKey(zone))
+	delete(p.domainIDsCached, zoneKey(zone))
+}
@@ -0,0 +1,87 @@
+package linode_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// blockingAPI blocks record listings after block was called, until the
+// returned channel is closed, and signals entered when a listing blocks.
+type blockingAPI struct {
+	linode.DomainAPI
+	mutex   sync.Mutex
+	blocked chan struct{}
+	entered chan struct{}
+}
+
+func (api *blockingAPI) block() chan struct{} {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+	api.blocked = make(chan struct{})
+	api.entered = make(chan struct{}, 1)
+	return api.blocked
+}
+
+func (api *blockingAPI) ListDomainRecords(ctx context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error) {
+	api.mutex.Lock()
+	blocked, entered := api.blocked, api.entered
+	api.mutex.Unlock()
+	if blocked != nil {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		select {
+		case <-blocked:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return api.DomainAPI.ListDomainRecords(ctx, domainID, opts)
+}
+
+func TestGetRecordsServesStaleWhileRefreshing(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	api := &blockingAPI{DomainAPI: fake}
+	provider := &linode.Provider{
+		API:                  api,
+		RecordCacheTTL:       10 * time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+	}
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+	mustGet(t, provider)
+	time.Sleep(20 * time.Millisecond)
+	release := api.block()
+	defer close(release)
+
+	// The first read after expiry starts the refresh, which blocks; the
+	// following reads must not wait for it.
+	mustGet(t, provider)
+	select {
+	case <-api.entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the records were not refreshed")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			records, err := provider.GetRecords(context.Background(), testZone)
+			if err != nil || len(records) != 1 {
+				t.Errorf("GetRecords = %d records, %v; want 1 record", len(records), err)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetRecords waited for the background refresh")
+	}
+}
@@ -0,0 +1,140 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// cacheFileVersion is increased when the format of cache files changes, so
+// that files written by other versions are ignored.
+const cacheFileVersion = 1
+
+// cacheFile is the format of Provider.CacheFile.
+type cacheFile struct {
+	Version int `json:"version"`
+	// Token is the fingerprint of the API token the cache was filled with.
+	Token   string                    `json:"token"`
+	Domains map[string]int            `json:"domains,omitempty"`
+	Zones   map[string]cacheFileEntry `json:"zones,omitempty"`
+}
+
+type cacheFileEntry struct {
+	Fetched time.Time         `json:"fetched"`
+	Records []cacheFileRecord `json:"records"`
+}
+
+type cacheFileRecord struct {
+	libdns.RR
+	ID int `json:"id,omitempty"`
+}
+
+// loadCacheFile fills the caches from CacheFile. A missing file, a file
+// written by another version or for another API token, a file whose token
+// can't be checked because TokenSource failed, and expired entries are
+// ignored. The caller must hold p.mutex.
+func (p *Provider) loadCacheFile(ctx context.Context) error {
+	if p.CacheFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(p.CacheFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read cache file: %v", err)
+	}
+	fingerprint, err := p.tokenFingerprint(ctx)
+	if err != nil {
+		return nil
+	}
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil || file.Version != cacheFileVersion || file.Token != fingerprint {
+		return nil
+	}
+	for zone, id := range file.Domains {
+		p.cacheDomainID(zone, id)
+	}
+	for zone, entry := range file.Zones {
+		if p.RecordCacheTTL <= 0 || time.Since(entry.Fetched) > p.RecordCacheTTL+p.StaleWhileRevalidate {
+			continue
+		}
+		records := make([]libdns.Record, 0, len(entry.Records))
+		for _, record := range entry.Records {
+			parsed, err := parseRecord(record.RR)
+			if err != nil {
+				parsed = record.RR
+			}
+			if record.ID != 0 {
+				parsed = withRecordID(parsed, record.ID)
+			}
+			records = append(records, parsed)
+		}
+		p.cacheRecords(zone, records)
+		p.recordCache[zoneKey(zone)].fetched = entry.Fetched
+	}
+	return nil
+}
+
+// saveCacheFile writes the caches to CacheFile. The caller must hold p.mutex.
+func (p *Provider) saveCacheFile() error {
+	if p.CacheFile == "" {
+		return nil
+	}
+	fingerprint, err := p.tokenFingerprint(context.Background())
+	if err != nil {
+		return fmt.Errorf("could not write cache file: %v", err)
+	}
+	file := cacheFile{
+		Version: cacheFileVersion,
+		Token:   fingerprint,
+		Domains: p.domainIDs,
+		Zones:   make(map[string]cacheFileEntry, len(p.recordCache)),
+	}
+	for zone, entry := range p.recordCache {
+		records := make([]cacheFileRecord, 0, len(entry.records))
+		for _, record := range entry.records {
+			id, _ := recordID(record)
+			records = append(records, cacheFileRecord{RR: record.RR(), ID: id})
+		}
+		file.Zones[zone] = cacheFileEntry{Fetched: entry.fetched, Records: records}
+	}
+	data, err := json.Marshal(file)
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first, so that concurrent readers never see a
+	// partially written cache.
+	tmp, err := os.CreateTemp(filepath.Dir(p.CacheFile), filepath.Base(p.CacheFile)+".*")
+	if err != nil {
+		return fmt.Errorf("could not write cache file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write cache file: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write cache file: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), p.CacheFile); err != nil {
+		return fmt.Errorf("could not write cache file: %v", err)
+	}
+	return nil
+}
+
+// Close writes the caches to CacheFile, if set, so that the next provider
+// using the file starts with them, and stops the worker pool.
+func (p *Provider) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.stopWorkerPool()
+	return p.saveCacheFile()
+}
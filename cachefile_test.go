@@ -0,0 +1,43 @@
+package linode_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+func TestCacheFileChecksTokenSource(t *testing.T) {
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("example.com")
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+	newProvider := func(token string) *linode.Provider {
+		return &linode.Provider{
+			API:            fake,
+			TokenSource:    linode.TokenSourceFunc(func(context.Context) (string, error) { return token, nil }),
+			CacheFile:      cacheFile,
+			RecordCacheTTL: time.Hour,
+		}
+	}
+	provider := newProvider("first")
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+	mustGet(t, provider)
+	if err := provider.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	opts := linodego.DomainRecordCreateOptions{Type: linodego.RecordTypeA, Name: "mail", Target: "192.0.2.2"}
+	if _, err := fake.CreateDomainRecord(context.Background(), domainID, opts); err != nil {
+		t.Fatal(err)
+	}
+
+	if records := mustGet(t, newProvider("first")); len(records) != 1 {
+		t.Errorf("with the same token, got %d records, want the 1 cached record", len(records))
+	}
+	if records := mustGet(t, newProvider("second")); len(records) != 2 {
+		t.Errorf("with another token, got %d records, want the 2 records of the API", len(records))
+	}
+}
@@ -0,0 +1,76 @@
+package linode
+
+import "time"
+
+// Limits enforced by the Linode Domains API and the DNS protocol.
+const (
+	maxLabelLength        = 63
+	maxDomainNameLength   = 255
+	maxRecordNameLength   = 100
+	maxRecordTargetLength = 65535
+	minPageSize           = 25
+	maxPageSize           = 500
+	defaultPageSize       = 100
+)
+
+// acceptedTTLs are the TTL values Linode stores; any other value is rounded
+// up to the nearest one. Zero means the domain's default TTL is used.
+var acceptedTTLs = []time.Duration{
+	0,
+	30 * time.Second,
+	2 * time.Minute,
+	5 * time.Minute,
+	time.Hour,
+	2 * time.Hour,
+	4 * time.Hour,
+	8 * time.Hour,
+	16 * time.Hour,
+	24 * time.Hour,
+	48 * time.Hour,
+	96 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	28 * 24 * time.Hour,
+}
+
+// roundTTL returns the TTL Linode stores for the given TTL.
+func roundTTL(ttl time.Duration) time.Duration {
+	for _, accepted := range acceptedTTLs {
+		if accepted >= ttl {
+			return accepted
+		}
+	}
+	return acceptedTTLs[len(acceptedTTLs)-1]
+}
+
+// Capabilities describes what the Linode Domains API supports, so that generic
+// tooling can adapt to it without hardcoding provider quirks.
+type Capabilities struct {
+	// RecordTypes lists the record types that can be created.
+	RecordTypes []string `json:"record_types"`
+	// TTLs lists the TTL values Linode stores. Other values are rounded up
+	// to the next accepted value, and 0 selects the domain's default TTL.
+	TTLs []time.Duration `json:"ttls"`
+	// MaxNameLength is the maximum length of a record name relative to the zone.
+	MaxNameLength int `json:"max_name_length"`
+	// MaxTargetLength is the maximum length of a record's target (data).
+	MaxTargetLength int `json:"max_target_length"`
+	// MinPageSize, MaxPageSize and DefaultPageSize are the pagination limits
+	// of list endpoints.
+	MinPageSize     int `json:"min_page_size"`
+	MaxPageSize     int `json:"max_page_size"`
+	DefaultPageSize int `json:"default_page_size"`
+}
+
+// Capabilities returns the capabilities of the Linode Domains API.
+func (p *Provider) Capabilities() Capabilities {
+	return Capabilities{
+		RecordTypes:     append([]string(nil), supportedRecordTypes...),
+		TTLs:            append([]time.Duration(nil), acceptedTTLs...),
+		MaxNameLength:   maxRecordNameLength,
+		MaxTargetLength: maxRecordTargetLength,
+		MinPageSize:     minPageSize,
+		MaxPageSize:     maxPageSize,
+		DefaultPageSize: defaultPageSize,
+	}
+}
@@ -0,0 +1,328 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/libdns/libdns"
+)
+
+// Changes is a precomputed change set, in the style of external-dns, to apply
+// with ApplyChanges.
+type Changes struct {
+	// Create are the records to add.
+	Create []libdns.Record
+	// UpdateOld are the records to replace by UpdateNew. The records of an
+	// RRset are replaced together, so the two lists need not be paired.
+	UpdateOld []libdns.Record
+	UpdateNew []libdns.Record
+	// Delete are the records to remove.
+	Delete []libdns.Record
+}
+
+// records returns the records of the changes in the order of their indexes
+// in RecordError: Create, UpdateOld, UpdateNew and then Delete.
+func (c Changes) records() []libdns.Record {
+	var records []libdns.Record
+	records = append(records, c.Create...)
+	records = append(records, c.UpdateOld...)
+	records = append(records, c.UpdateNew...)
+	return append(records, c.Delete...)
+}
+
+// withRecords returns changes of the same sizes as c with the records, in the
+// order of records.
+func (c Changes) withRecords(records []libdns.Record) Changes {
+	next := func(n int) []libdns.Record {
+		part := records[:n:n]
+		records = records[n:]
+		return part
+	}
+	return Changes{
+		Create:    next(len(c.Create)),
+		UpdateOld: next(len(c.UpdateOld)),
+		UpdateNew: next(len(c.UpdateNew)),
+		Delete:    next(len(c.Delete)),
+	}
+}
+
+// rrsetChanges are the changes to a single RRset.
+type rrsetChanges struct {
+	key       rrsetKey
+	removals  []libdns.Record
+	additions []libdns.Record
+	// existing are the live records matching the removals.
+	existing []libdns.Record
+	// removalIndexes and additionIndexes are the indexes of the removals and
+	// additions in the change set, see Changes.records.
+	removalIndexes  []int
+	additionIndexes []int
+}
+
+// ApplyChanges applies a change set to the zone with the fewest writes: a
+// record that is removed and added again with other data or TTL is updated in
+// place. The removals are matched against the live records by name, type and
+// data before anything is written, and if any of them is not in the zone the
+// change set is rejected without writing, so that a stale change set never
+// leaves an RRset half changed. The RRsets are then written one after another,
+// and if a write fails, the writes already made to its RRset are undone and
+// get OutcomeRolledBack. It returns a result for every write, and
+// OutcomeSkipped for the writes not attempted after a failure; with
+// ContinueOnError, the RRsets after a failed one are still written. The Index
+// of a RecordError is the position of the record in Create, UpdateOld,
+// UpdateNew and Delete, counted in that order.
+func (p *Provider) ApplyChanges(ctx context.Context, zone string, changes Changes) ([]RecordResult, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	return p.inParentZone(ctx, zone, changes.records(), func(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+		return p.applyChanges(ctx, zone, changes.withRecords(records))
+	})
+}
+
+func (p *Provider) applyChanges(ctx context.Context, zone string, changes Changes) ([]RecordResult, error) {
+	var removals, additions []libdns.Record
+	var removalIndexes, additionIndexes []int
+	index := 0
+	for _, part := range []struct {
+		records []libdns.Record
+		removal bool
+	}{{changes.Create, false}, {changes.UpdateOld, true}, {changes.UpdateNew, false}, {changes.Delete, true}} {
+		for _, record := range part.records {
+			if part.removal {
+				removals, removalIndexes = append(removals, record), append(removalIndexes, index)
+			} else {
+				additions, additionIndexes = append(additions, record), append(additionIndexes, index)
+			}
+			index++
+		}
+	}
+	if err := validateRecords(zone, additions); err != nil {
+		return nil, err
+	}
+	if err := p.checkNames(zone, additions); err != nil {
+		return nil, err
+	}
+	if err := p.checkScope(zone, append(append([]libdns.Record(nil), removals...), additions...)); err != nil {
+		return nil, err
+	}
+	if err := p.checkWritable(zone); err != nil {
+		return nil, err
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	existing, err := p.listDomainRecords(ctx, zone, domainID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := groupChanges(zone, existing, removals, removalIndexes, additions, additionIndexes)
+	if err != nil {
+		return nil, err
+	}
+	type plannedGroup struct {
+		changes rrsetChanges
+		plan    setPlan
+	}
+	planned := make([]plannedGroup, 0, len(groups))
+	destructive := DestructiveChanges{Zone: zone, Operation: "apply"}
+	for _, group := range groups {
+		plan := p.planSet(zone, group.existing, group.additions)
+		if len(group.additions) == 0 {
+			plan.deletions = group.existing
+		}
+		destructive.Deletes = append(destructive.Deletes, plan.deletions...)
+		for i, record := range group.additions {
+			if plan.matches[i] != nil && !plan.unchanged[i] && group.key.name == "@" {
+				destructive.ApexChanges = append(destructive.ApexChanges, record)
+			}
+		}
+		planned = append(planned, plannedGroup{changes: group, plan: plan})
+	}
+	if err := p.checkDeletions(ctx, len(destructive.Deletes), len(existing)); err != nil {
+		return nil, err
+	}
+	if isDryRun(ctx) {
+		var results []RecordResult
+		for _, group := range planned {
+			results = append(results, group.plan.results(group.changes.additions)...)
+		}
+		return results, nil
+	}
+	if err := p.confirm(ctx, destructive); err != nil {
+		return nil, err
+	}
+	defer p.invalidateRecords(zone)
+	var results []RecordResult
+	defer func() { p.notifyWebhook(zone, "apply", results) }()
+	var batchErr BatchError
+	for g, group := range planned {
+		failed, err := p.applyRRSetChanges(ctx, zone, domainID, group.changes, group.plan, &results)
+		if err != nil {
+			if err := p.handleRecordError(&batchErr, failed.index, failed.record, err); err != nil {
+				for _, rest := range planned[g+1:] {
+					results = append(results, plannedResults(rest.changes.additions, OutcomeSkipped)...)
+					results = append(results, plannedResults(rest.plan.deletions, OutcomeSkipped)...)
+				}
+				return results, err
+			}
+		}
+	}
+	return results, batchErr.errOrNil()
+}
+
+// groupChanges groups the changes by RRset, in the order the RRsets first
+// appear, and matches the removals against the existing records.
+func groupChanges(zone string, existing, removals []libdns.Record, removalIndexes []int, additions []libdns.Record, additionIndexes []int) ([]rrsetChanges, error) {
+	var groups []rrsetChanges
+	index := make(map[rrsetKey]int)
+	group := func(record libdns.Record) *rrsetChanges {
+		key := newRRSetKey(zone, record)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, rrsetChanges{key: key})
+		}
+		return &groups[i]
+	}
+	for i, record := range removals {
+		g := group(record)
+		g.removals = append(g.removals, record)
+		g.removalIndexes = append(g.removalIndexes, removalIndexes[i])
+	}
+	for i, record := range additions {
+		g := group(record)
+		g.additions = append(g.additions, record)
+		g.additionIndexes = append(g.additionIndexes, additionIndexes[i])
+	}
+	claimed := make(map[int]bool)
+	for i := range groups {
+		g := &groups[i]
+		for _, removal := range g.removals {
+			key := newRecordKey(zone, removal)
+			var match libdns.Record
+			for _, candidate := range existing {
+				if id, ok := recordID(candidate); ok && !claimed[id] && newRecordKey(zone, candidate) == key {
+					claimed[id] = true
+					match = candidate
+					break
+				}
+			}
+			if match == nil {
+				rr := removal.RR()
+				return nil, fmt.Errorf("could not apply changes to %s %s: record %q is not in the zone", g.key.name, g.key.recordType, rr.Data)
+			}
+			g.existing = append(g.existing, match)
+		}
+	}
+	return groups, nil
+}
+
+// failedChange is the change of a change set whose write failed.
+type failedChange struct {
+	index  int
+	record libdns.Record
+}
+
+// applyRRSetChanges writes the planned changes of an RRset, appending a result
+// for every write. At the first failure, it undoes the writes already made to
+// the RRset, marks them OutcomeRolledBack and the writes not attempted
+// OutcomeSkipped, and returns the failed change.
+func (p *Provider) applyRRSetChanges(ctx context.Context, zone string, domainID int, changes rrsetChanges, plan setPlan, results *[]RecordResult) (failedChange, error) {
+	type write struct {
+		result int
+		undo   func() error
+	}
+	// writes are the writes made so far, with the index of their results.
+	var writes []write
+	written := func(undo func() error) {
+		writes = append(writes, write{result: len(*results) - 1, undo: undo})
+	}
+	fail := func(failed failedChange, err error, skipped []RecordResult) (failedChange, error) {
+		*results = append(append(*results, RecordResult{Record: failed.record, Outcome: OutcomeFailed, Err: err}), skipped...)
+		for i := len(writes) - 1; i >= 0; i-- {
+			if rollbackErr := writes[i].undo(); rollbackErr != nil {
+				return failed, fmt.Errorf("%w; could not roll back the other changes to %s %s: %v", err, changes.key.name, changes.key.recordType, rollbackErr)
+			}
+			(*results)[writes[i].result].Outcome = OutcomeRolledBack
+		}
+		return failed, err
+	}
+	for i, record := range changes.additions {
+		match := plan.matches[i]
+		if match != nil && plan.unchanged[i] {
+			*results = append(*results, RecordResult{Record: match, Outcome: OutcomeNoOp})
+			continue
+		}
+		var result libdns.Record
+		var err error
+		if match == nil {
+			result, err = p.createDomainRecord(ctx, zone, domainID, record)
+		} else {
+			result, err = p.updateDomainRecord(ctx, zone, domainID, record, match)
+		}
+		if err != nil {
+			skipped := plannedResults(changes.additions[i+1:], OutcomeSkipped)
+			skipped = append(skipped, plannedResults(plan.deletions, OutcomeSkipped)...)
+			return fail(failedChange{index: changes.additionIndexes[i], record: record}, err, skipped)
+		}
+		if match == nil {
+			*results = append(*results, RecordResult{Record: result, Outcome: OutcomeCreated})
+			written(func() error { return p.deleteDomainRecord(ctx, zone, domainID, result) })
+		} else {
+			*results = append(*results, RecordResult{Record: result, Outcome: OutcomeUpdated})
+			written(func() error {
+				if match.RR().TTL == 0 && result.RR().TTL != 0 {
+					// Updates can't set a zero TTL, so the record is replaced.
+					if err := p.deleteDomainRecord(ctx, zone, domainID, result); err != nil {
+						return err
+					}
+					_, err := p.createDomainRecord(ctx, zone, domainID, match)
+					return err
+				}
+				_, err := p.updateDomainRecord(ctx, zone, domainID, match, result)
+				return err
+			})
+		}
+	}
+	for i, record := range plan.deletions {
+		err := p.deleteDomainRecord(ctx, zone, domainID, record)
+		if err != nil && errorStatus(err) != http.StatusNotFound {
+			return fail(failedChange{index: changes.removalIndex(record), record: record}, err, plannedResults(plan.deletions[i+1:], OutcomeSkipped))
+		}
+		*results = append(*results, RecordResult{Record: record, Outcome: OutcomeDeleted})
+		if err == nil {
+			written(func() error {
+				_, err := p.createDomainRecord(ctx, zone, domainID, record)
+				return err
+			})
+		}
+	}
+	return failedChange{}, nil
+}
+
+// removalIndex returns the index in the change set of the removal matching
+// the existing record.
+func (c rrsetChanges) removalIndex(record libdns.Record) int {
+	id, _ := recordID(record)
+	for i, existing := range c.existing {
+		if existingID, _ := recordID(existing); existingID == id {
+			return c.removalIndexes[i]
+		}
+	}
+	return -1
+}
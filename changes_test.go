@@ -0,0 +1,117 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// failingCreateAPI fails the creation of records with the target.
+type failingCreateAPI struct {
+	linode.DomainAPI
+	target string
+}
+
+func (api *failingCreateAPI) CreateDomainRecord(ctx context.Context, domainID int, opts linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error) {
+	if opts.Target == api.target {
+		return nil, &linodego.Error{Code: 400, Message: "creation failed"}
+	}
+	return api.DomainAPI.CreateDomainRecord(ctx, domainID, opts)
+}
+
+func outcomes(results []linode.RecordResult) []linode.Outcome {
+	outcomes := make([]linode.Outcome, len(results))
+	for i, result := range results {
+		outcomes[i] = result.Outcome
+	}
+	return outcomes
+}
+
+func TestApplyChangesRollsBackRRSet(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: &failingCreateAPI{DomainAPI: fake, target: "192.0.2.3"}}
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+	before := zoneContents(t, provider)
+
+	// The update of 192.0.2.1 and the creation of 192.0.2.2 are undone when
+	// creating 192.0.2.3 fails.
+	results, err := provider.ApplyChanges(context.Background(), testZone, linode.Changes{
+		UpdateOld: []libdns.Record{mustParse(t, "www", "A", "192.0.2.1")},
+		UpdateNew: []libdns.Record{
+			libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.1")},
+			mustParse(t, "www", "A", "192.0.2.2"),
+			mustParse(t, "www", "A", "192.0.2.3"),
+		},
+	})
+	if err == nil {
+		t.Fatal("ApplyChanges succeeded, want the creation error")
+	}
+	want := []linode.Outcome{linode.OutcomeRolledBack, linode.OutcomeRolledBack, linode.OutcomeFailed}
+	if got := outcomes(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+	if after := zoneContents(t, provider); fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Errorf("zone after the failed change = %q, want %q", after, before)
+	}
+}
+
+func TestApplyChangesSkipsRRSetsAfterFailure(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: &failingCreateAPI{DomainAPI: fake, target: "192.0.2.1"}}
+	results, err := provider.ApplyChanges(context.Background(), testZone, linode.Changes{
+		Create: []libdns.Record{mustParse(t, "www", "A", "192.0.2.1"), mustParse(t, "mail", "A", "192.0.2.2")},
+	})
+	if err == nil {
+		t.Fatal("ApplyChanges succeeded, want the creation error")
+	}
+	want := []linode.Outcome{linode.OutcomeFailed, linode.OutcomeSkipped}
+	if got := outcomes(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+}
+
+func TestApplyChangesErrorIndex(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: &failingCreateAPI{DomainAPI: fake, target: "192.0.2.9"}, ContinueOnError: true}
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+
+	// The RRset of www is written first, so the failing record is the third
+	// result, but the second record of the change set.
+	_, err := provider.ApplyChanges(context.Background(), testZone, linode.Changes{
+		Create:    []libdns.Record{mustParse(t, "mail", "A", "192.0.2.2"), mustParse(t, "ftp", "A", "192.0.2.9")},
+		UpdateOld: []libdns.Record{mustParse(t, "www", "A", "192.0.2.1")},
+		UpdateNew: []libdns.Record{mustParse(t, "www", "A", "192.0.2.3")},
+	})
+	var batchErr *linode.BatchError
+	if !errors.As(err, &batchErr) || len(batchErr.Errors) != 1 {
+		t.Fatalf("ApplyChanges error = %v, want a BatchError with one error", err)
+	}
+	if index := batchErr.Errors[0].Index; index != 1 {
+		t.Errorf("RecordError.Index = %d, want 1", index)
+	}
+}
+
+func TestApplyChangesFindsParentZone(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	provider.FindParentZone = true
+	_, err := provider.ApplyChanges(context.Background(), "sub.example.com.", linode.Changes{
+		Create: []libdns.Record{mustParse(t, "host", "A", "192.0.2.1")},
+	})
+	if err != nil {
+		t.Fatalf("ApplyChanges: %v", err)
+	}
+	if records := fake.Records(domainID); len(records) != 1 || records[0].Name != "host.sub" {
+		t.Errorf("records = %+v, want host.sub", records)
+	}
+}
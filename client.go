@@ -2,9 +2,13 @@ package linode
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/netip"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -13,22 +17,138 @@ import (
 	"github.com/linode/linodego"
 )
 
-func (p *Provider) init(ctx context.Context) {
+func (p *Provider) init(ctx context.Context) error {
 	p.once.Do(func() {
-		p.client = linodego.NewClient(http.DefaultClient)
-		if p.APIToken != "" {
-			p.client.SetToken(p.APIToken)
+		httpClient, err := p.httpClient()
+		if err != nil {
+			p.initErr = err
+			return
 		}
-		if p.APIURL != "" {
-			p.client.SetBaseURL(p.APIURL)
+		p.client = linodego.NewClient(httpClient)
+		if token := p.apiToken(); token != "" {
+			p.client.SetToken(token)
 		}
-		if p.APIVersion != "" {
-			p.client.SetAPIVersion(p.APIVersion)
+		if url := p.apiURL(); url != "" {
+			p.client.SetBaseURL(url)
 		}
+		if version := p.apiVersion(); version != "" {
+			p.client.SetAPIVersion(version)
+		}
+		if p.UserAgent != "" {
+			p.client.SetUserAgent(linodego.DefaultUserAgent + " " + p.UserAgent)
+		}
+		if p.RetryMaxWait > 0 {
+			p.client.SetRetryMaxWaitTime(p.RetryMaxWait)
+		}
+		if p.RetryWait > 0 {
+			p.client.SetRetryWaitTime(p.RetryWait)
+		}
+		if p.MaxRetries > 0 {
+			p.client.SetRetryCount(p.MaxRetries)
+			p.client.AddRetryCondition(retryTransient)
+		}
+		p.client.SetRetryAfter(retryAfter)
+		p.client.OnBeforeRequest(func(r *linodego.Request) error {
+			p.countRequest(r.Method, r.URL, r.Attempt)
+			if r.Attempt > 1 {
+				r.SetContext(withRetry(r.Context()))
+			}
+			if err := p.authorize(r); err != nil {
+				return err
+			}
+			return p.waitRateLimit(r.Context())
+		})
+		p.api = &p.client
+		if p.API != nil {
+			p.api = p.API
+		}
+		p.initErr = p.loadCacheFile(ctx)
 	})
+	return p.initErr
+}
+
+// httpClient returns the HTTP client used to talk to the Linode API.
+func (p *Provider) httpClient() (*http.Client, error) {
+	if p.HTTPClient != nil {
+		client := *p.HTTPClient
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &statsTransport{provider: p, base: base}
+		return &client, nil
+	}
+	tlsConfig, err := p.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil && p.MaxIdleConns == 0 && p.IdleConnTimeout == 0 && p.TLSHandshakeTimeout == 0 && !p.ForceHTTP2 {
+		return &http.Client{Transport: &statsTransport{provider: p, base: http.DefaultTransport}}, nil
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.MaxIdleConns > 0 {
+		transport.MaxIdleConns = p.MaxIdleConns
+		transport.MaxIdleConnsPerHost = p.MaxIdleConns
+	}
+	if p.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = p.IdleConnTimeout
+	}
+	if p.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = p.TLSHandshakeTimeout
+	}
+	transport.TLSClientConfig = tlsConfig
+	transport.ForceAttemptHTTP2 = p.ForceHTTP2
+	return &http.Client{Transport: &statsTransport{provider: p, base: transport}}, nil
+}
+
+// tlsConfig returns the TLS configuration for custom root CAs and client
+// certificates, or nil if neither is configured.
+func (p *Provider) tlsConfig() (*tls.Config, error) {
+	if p.RootCAs == nil && p.CACertFile == "" && len(p.ClientCertificates) == 0 && p.ClientCertFile == "" {
+		return nil, nil
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		RootCAs:      p.RootCAs,
+		Certificates: append([]tls.Certificate(nil), p.ClientCertificates...),
+	}
+	if p.CACertFile != "" {
+		pem, err := os.ReadFile(p.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA certificates: %v", err)
+		}
+		if config.RootCAs == nil {
+			config.RootCAs = x509.NewCertPool()
+		}
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no CA certificates found in %s", p.CACertFile)
+		}
+	}
+	if p.ClientCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.ClientCertFile, p.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client certificate: %v", err)
+		}
+		config.Certificates = append(config.Certificates, cert)
+	}
+	return config, nil
 }
 
 func (p *Provider) getDomainIDByZone(ctx context.Context, zone string) (int, error) {
+	if id, ok := p.cachedDomainID(zone); ok {
+		return id, nil
+	}
+	id, err := p.lookupDomainID(ctx, zone)
+	if err != nil {
+		return 0, err
+	}
+	p.cacheDomainID(zone, id)
+	return id, nil
+}
+
+// lookupDomainID finds the ID of the zone's domain with the API, without
+// using the domain ID cache, so the caller need not hold p.mutex.
+func (p *Provider) lookupDomainID(ctx context.Context, zone string) (int, error) {
 	f := linodego.Filter{}
 	f.AddField(linodego.Eq, "domain", libdns.AbsoluteName(zone, ""))
 	filter, err := f.MarshalJSON()
@@ -36,117 +156,379 @@ func (p *Provider) getDomainIDByZone(ctx context.Context, zone string) (int, err
 		return 0, err
 	}
 	listOptions := linodego.NewListOptions(0, string(filter))
-	domains, err := p.client.ListDomains(ctx, listOptions)
+	domains, err := p.api.ListDomains(ctx, listOptions)
 	if err != nil {
-		return 0, fmt.Errorf("could not list domains: %v", err)
+		return 0, fmt.Errorf("could not list domains: %w", err)
 	}
 	if len(domains) == 0 {
-		return 0, fmt.Errorf("could not find the domain provided")
+		return 0, fmt.Errorf("%w: %s", ErrZoneNotFound, zone)
+	}
+	if len(domains) > 1 {
+		ambiguous := &AmbiguousDomainError{Zone: zone}
+		for _, domain := range domains {
+			ambiguous.Candidates = append(ambiguous.Candidates, DomainCandidate{ID: domain.ID, Status: domain.Status})
+		}
+		return 0, ambiguous
 	}
 	return domains[0].ID, nil
 }
 
+// apiPost makes a POST request to an API endpoint that linodego has no method
+// for, decoding the response into result.
+func (p *Provider) apiPost(ctx context.Context, endpoint string, body, result any) error {
+	if p.API != nil {
+		return fmt.Errorf("POST %s is not supported by the configured API", endpoint)
+	}
+	resp, err := p.client.R(ctx).SetBody(body).SetResult(result).Post(endpoint)
+	if err != nil {
+		return linodego.NewError(err)
+	}
+	if resp.IsError() {
+		return linodego.NewError(resp)
+	}
+	return nil
+}
+
+// readRecords reads the records of the zone, with a zone transfer if enabled.
+// The caller must hold p.mutex.
+func (p *Provider) readRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	records, err := p.readDomainRecords(ctx, zone, domainID)
+	if errors.Is(err, ErrZoneNotFound) {
+		// The domain was deleted, or recreated with a new ID.
+		p.uncacheDomainID(zone)
+	}
+	return records, err
+}
+
+// readDomainRecords reads the records of the zone's domain, with a zone
+// transfer if enabled. It uses no state guarded by p.mutex.
+func (p *Provider) readDomainRecords(ctx context.Context, zone string, domainID int) ([]libdns.Record, error) {
+	if p.UseAXFR {
+		if records, err := p.transferRecords(ctx, zone); err == nil {
+			return records, nil
+		}
+	}
+	records, err := p.listZoneRecords(ctx, zone, domainID)
+	if err != nil || !p.ResolveDefaultTTL {
+		return records, err
+	}
+	return p.resolveDefaultTTLs(ctx, domainID, records)
+}
+
+// resolveDefaultTTLs replaces the zero TTLs of records that inherit the
+// domain's TTL with that TTL.
+func (p *Provider) resolveDefaultTTLs(ctx context.Context, domainID int, records []libdns.Record) ([]libdns.Record, error) {
+	var ttl time.Duration
+	for i, record := range records {
+		if record.RR().TTL != 0 {
+			continue
+		}
+		if ttl == 0 {
+			var err error
+			if ttl, err = p.domainTTL(ctx, domainID); err != nil {
+				return nil, err
+			}
+		}
+		records[i] = withTTL(record, ttl)
+	}
+	return records, nil
+}
+
+// fetchRecords lists the records of the zone with the API.
+// The caller must hold p.mutex.
+func (p *Provider) fetchRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	records, err := p.listZoneRecords(ctx, zone, domainID)
+	if errors.Is(err, ErrZoneNotFound) {
+		p.uncacheDomainID(zone)
+	}
+	return records, err
+}
+
+// listZoneRecords lists the records of the zone's domain, returning an error
+// wrapping ErrZoneNotFound if the domain no longer exists.
+func (p *Provider) listZoneRecords(ctx context.Context, zone string, domainID int) ([]libdns.Record, error) {
+	records, err := p.listDomainRecords(ctx, zone, domainID)
+	if errorStatus(err) == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s: %w", ErrZoneNotFound, zone, err)
+	}
+	return records, err
+}
+
+// listPageSize is the largest page size the Linode API accepts, so that large
+// zones are listed with as few requests as possible.
+const listPageSize = 500
+
+// listDomainRecords lists all records of the domain, requesting every page.
 func (p *Provider) listDomainRecords(ctx context.Context, zone string, domainID int) ([]libdns.Record, error) {
-	listOptions := linodego.NewListOptions(0, "")
-	linodeRecords, err := p.client.ListDomainRecords(ctx, domainID, listOptions)
+	return p.listFilteredDomainRecords(ctx, zone, domainID, "")
+}
+
+// listFilteredDomainRecords lists the records of the domain that match the
+// X-Filter of the Linode API, requesting every page.
+func (p *Provider) listFilteredDomainRecords(ctx context.Context, zone string, domainID int, filter string) ([]libdns.Record, error) {
+	listOptions := linodego.NewListOptions(0, filter)
+	listOptions.PageSize = listPageSize
+	linodeRecords, err := p.api.ListDomainRecords(ctx, domainID, listOptions)
 	if err != nil {
-		return nil, fmt.Errorf("could not list domain records: %v", err)
+		return nil, fmt.Errorf("could not list domain records: %w", err)
+	}
+	if len(linodeRecords) < listOptions.Results {
+		return nil, fmt.Errorf("could not list domain records: got %d of %d", len(linodeRecords), listOptions.Results)
 	}
+	return p.convertRecords(zone, linodeRecords)
+}
+
+// convertRecords converts the records of a domain, see malformedRecord.
+func (p *Provider) convertRecords(zone string, linodeRecords []linodego.DomainRecord) ([]libdns.Record, error) {
 	records := make([]libdns.Record, 0, len(linodeRecords))
 	for _, linodeRecord := range linodeRecords {
 		record := convertToLibdnsRecord(zone, &linodeRecord)
-		if record != nil {
-			records = append(records, record)
+		if rr, ok := record.(libdns.RR); ok {
+			if err := p.malformedRecord(zone, linodeRecord.ID, rr); err != nil {
+				return nil, err
+			}
 		}
+		records = append(records, record)
 	}
 	return records, nil
 }
 
-func (p *Provider) createOrUpdateDomainRecord(ctx context.Context, zone string, domainID int, record libdns.Record) (libdns.Record, error) {
-	// Check if this record has an ID (indicating it exists)
-	if providerData, ok := getProviderData(record); ok {
-		if id, exists := providerData["id"]; exists {
-			updatedRecord, err := p.updateDomainRecord(ctx, zone, domainID, record, id.(string))
-			if err != nil {
-				return nil, err
-			}
-			return updatedRecord, nil
+// malformedRecord reports a record whose data could not be parsed to
+// OnMalformedRecord, and returns an error with StrictParsing.
+func (p *Provider) malformedRecord(zone string, id int, rr libdns.RR) error {
+	if p.OnMalformedRecord != nil {
+		p.OnMalformedRecord(zone, rr)
+	}
+	if p.StrictParsing {
+		return fmt.Errorf("%w: %s %s %q (ID %d) in zone %s", ErrMalformedRecord, rr.Name, rr.Type, rr.Data, id, zone)
+	}
+	return nil
+}
+
+// setAfterConflict re-resolves a record whose update conflicted by its name, type
+// and data, and updates the matching record, or creates it if none matches.
+func (p *Provider) setAfterConflict(ctx context.Context, zone string, domainID int, record libdns.Record) (libdns.Record, Outcome, error) {
+	matches, err := p.findMatchingRecords(ctx, zone, domainID, record)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(matches) == 0 {
+		addedRecord, err := p.createDomainRecord(ctx, zone, domainID, record)
+		if err != nil {
+			return nil, "", err
 		}
+		return addedRecord, OutcomeCreated, nil
 	}
-	
-	// No ID found, create new record
-	addedRecord, err := p.createDomainRecord(ctx, zone, domainID, record)
+	updatedRecord, err := p.updateDomainRecord(ctx, zone, domainID, record, matches[0])
+	if err != nil {
+		return nil, "", err
+	}
+	return updatedRecord, OutcomeUpdated, nil
+}
+
+// findMatchingRecords returns the records of the zone with the same name, type
+// and data as the given record.
+func (p *Provider) findMatchingRecords(ctx context.Context, zone string, domainID int, record libdns.Record) ([]libdns.Record, error) {
+	records, err := p.listDomainRecords(ctx, zone, domainID)
 	if err != nil {
 		return nil, err
 	}
-	return addedRecord, nil
+	key := newRecordKey(zone, record)
+	var matches []libdns.Record
+	for _, candidate := range records {
+		if _, ok := recordID(candidate); ok && newRecordKey(zone, candidate) == key {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches, nil
 }
 
 func (p *Provider) createDomainRecord(ctx context.Context, zone string, domainID int, record libdns.Record) (libdns.Record, error) {
-	rr := record.RR()
-	
-	addedLinodeRecord, err := p.client.CreateDomainRecord(ctx, domainID, linodego.DomainRecordCreateOptions{
-		Type:   linodego.DomainRecordType(rr.Type),
-		Name:   libdns.RelativeName(rr.Name, zone),
-		Target: rr.Data,
-		TTLSec: int(rr.TTL.Seconds()),
-	})
+	ctx = withRecord(ctx, record)
+	opts := recordOptions(zone, record)
+	opts.TTLSec = p.ttlSec(zone, record.RR().TTL)
+	addedLinodeRecord, err := p.api.CreateDomainRecord(ctx, domainID, opts)
 	if err != nil {
 		return nil, err
 	}
 	return mergeWithExistingLibdnsRecord(zone, record, addedLinodeRecord), nil
 }
 
-func (p *Provider) updateDomainRecord(ctx context.Context, zone string, domainID int, record libdns.Record, recordIDStr string) (libdns.Record, error) {
+// recordOptions returns the fields Linode stores for the record, except its
+// TTL. The preference of MX records, the service, protocol, priority,
+// weight and port of SRV records and the tag of CAA records are sent in
+// their own fields, as Linode expects, rather than in the target.
+func recordOptions(zone string, record libdns.Record) linodego.DomainRecordCreateOptions {
 	rr := record.RR()
-	recordID, err := strconv.Atoi(recordIDStr)
-	if err != nil {
-		return nil, err
-	}
-	updatedLinodeRecord, err := p.client.UpdateDomainRecord(ctx, domainID, recordID, linodego.DomainRecordUpdateOptions{
+	opts := linodego.DomainRecordCreateOptions{
 		Type:   linodego.DomainRecordType(rr.Type),
-		Name:   libdns.RelativeName(rr.Name, zone),
-		Target: rr.Data,
-		TTLSec: int(rr.TTL.Seconds()),
-	})
+		Name:   relativeName(rr.Name, zone),
+		Target: linodeTarget(rr),
+	}
+	if _, ok := record.(libdns.RR); ok {
+		if parsed, err := rr.Parse(); err == nil {
+			record = parsed
+		}
+	}
+	switch r := record.(type) {
+	case libdns.MX:
+		preference := int(r.Preference)
+		opts.Target = r.Target
+		opts.Priority = &preference
+	case libdns.SRV:
+		// Linode prepends the underscores itself.
+		service, protocol := strings.TrimPrefix(r.Service, "_"), strings.TrimPrefix(r.Transport, "_")
+		priority, weight, port := int(r.Priority), int(r.Weight), int(r.Port)
+		opts.Name = relativeName(r.Name, zone)
+		opts.Target = r.Target
+		opts.Service, opts.Protocol = &service, &protocol
+		opts.Priority, opts.Weight, opts.Port = &priority, &weight, &port
+	case libdns.CAA:
+		// Linode does not store CAA flags, and quotes the value itself.
+		tag := r.Tag
+		opts.Target = r.Value
+		opts.Tag = &tag
+	}
+	return opts
+}
+
+// updateDomainRecord updates the existing record to match record. Only the
+// fields that differ are sent, so that attributes of the existing record the
+// caller left unset, such as a zero TTL, are preserved.
+func (p *Provider) updateDomainRecord(ctx context.Context, zone string, domainID int, record, existing libdns.Record) (libdns.Record, error) {
+	ctx = withRecord(ctx, record)
+	recordID, ok := recordID(existing)
+	if !ok {
+		return nil, fmt.Errorf("record does not have provider data with ID")
+	}
+	rr, existingRR := record.RR(), existing.RR()
+	want, have := recordOptions(zone, record), recordOptions(zone, existing)
+	var opts linodego.DomainRecordUpdateOptions
+	if !strings.EqualFold(rr.Type, existingRR.Type) {
+		opts.Type = want.Type
+	}
+	if normalizeName(zone, rr.Name) != normalizeName(zone, existingRR.Name) {
+		opts.Name = want.Name
+	}
+	if want.Target != have.Target {
+		opts.Target = want.Target
+	}
+	opts.Priority = changedInt(want.Priority, have.Priority)
+	opts.Weight = changedInt(want.Weight, have.Weight)
+	opts.Port = changedInt(want.Port, have.Port)
+	opts.Service = changedString(want.Service, have.Service)
+	opts.Protocol = changedString(want.Protocol, have.Protocol)
+	opts.Tag = changedString(want.Tag, have.Tag)
+	if ttl := p.ttlSec(zone, rr.TTL); ttl != 0 && ttl != int(existingRR.TTL.Seconds()) {
+		opts.TTLSec = ttl
+	}
+	if opts == (linodego.DomainRecordUpdateOptions{}) {
+		return existing, nil
+	}
+	updatedLinodeRecord, err := p.api.UpdateDomainRecord(ctx, domainID, recordID, opts)
 	if err != nil {
-		return nil, err
+		return nil, recordNotFoundError(err)
 	}
 	return mergeWithExistingLibdnsRecord(zone, record, updatedLinodeRecord), nil
 }
 
-func (p *Provider) deleteDomainRecord(ctx context.Context, domainID int, record libdns.Record) error {
-	providerData, ok := getProviderData(record)
+// changedInt returns want if it is set and differs from have, or nil.
+func changedInt(want, have *int) *int {
+	if want == nil || (have != nil && *want == *have) {
+		return nil
+	}
+	return want
+}
+
+// changedString returns want if it is set and differs from have, or nil.
+func changedString(want, have *string) *string {
+	if want == nil || (have != nil && *want == *have) {
+		return nil
+	}
+	return want
+}
+
+func (p *Provider) deleteDomainRecord(ctx context.Context, zone string, domainID int, record libdns.Record) error {
+	ctx = withRecord(ctx, record)
+	id, ok := recordID(record)
 	if !ok {
-		return fmt.Errorf("record does not have provider data with ID")
+		return p.deleteMatchingRecords(ctx, zone, domainID, record)
 	}
-	
-	id, exists := providerData["id"]
-	if !exists {
-		return fmt.Errorf("record does not have ID in provider data")
+
+	err := p.api.DeleteDomainRecord(ctx, domainID, id)
+	if isConflictError(err) {
+		// The record was changed or removed by someone else since it was read,
+		// so look it up again and retry once.
+		matches, findErr := p.findMatchingRecords(ctx, zone, domainID, record)
+		if findErr != nil || len(matches) == 0 {
+			return err
+		}
+		id, _ = recordID(matches[0])
+		return p.api.DeleteDomainRecord(ctx, domainID, id)
 	}
-	
-	recordID, err := strconv.Atoi(id.(string))
+	return err
+}
+
+// deleteMatchingRecords deletes the records of the domain that match a record
+// without a Linode ID, see matchesDeletion. It returns a not found error if
+// none match.
+func (p *Provider) deleteMatchingRecords(ctx context.Context, zone string, domainID int, record libdns.Record) error {
+	records, err := p.listDomainRecords(ctx, zone, domainID)
 	if err != nil {
 		return err
 	}
-	return p.client.DeleteDomainRecord(ctx, domainID, recordID)
+	found := false
+	for _, candidate := range records {
+		id, ok := recordID(candidate)
+		if !ok || !matchesDeletion(zone, record, candidate) {
+			continue
+		}
+		found = true
+		if err := p.api.DeleteDomainRecord(ctx, domainID, id); err != nil && errorStatus(err) != http.StatusNotFound {
+			return err
+		}
+	}
+	if !found {
+		return errNoMatchingRecord
+	}
+	return nil
+}
+
+// ttlSec returns the TTL in seconds to send to Linode for a record of the zone.
+func (p *Provider) ttlSec(zone string, ttl time.Duration) int {
+	return int(p.ttlDuration(zone, ttl).Seconds())
+}
+
+// ttlDuration returns the TTL to send to Linode for a record of the zone.
+func (p *Provider) ttlDuration(zone string, ttl time.Duration) time.Duration {
+	if ttl == 0 {
+		ttl = p.defaultTTL(zone)
+	}
+	return ttl
 }
 
 func convertToLibdnsRecord(zone string, linodeRecord *linodego.DomainRecord) libdns.Record {
 	name := libdns.RelativeName(linodeRecord.Name, zone)
 	ttl := time.Duration(linodeRecord.TTLSec) * time.Second
-	recordType := string(linodeRecord.Type)
 	data := linodeRecord.Target
-	
+
 	// Store provider-specific data (like the record ID) in ProviderData
-	providerData := map[string]interface{}{
-		"id": strconv.Itoa(linodeRecord.ID),
-	}
-	
-	// Convert to specific record types based on DNS record type
-	switch strings.ToUpper(recordType) {
-	case "A", "AAAA":
+	providerData := recordData{ID: linodeRecord.ID}
+
+	// Convert to specific record types based on DNS record type. Linode returns
+	// the priority, weight, port, service, protocol and tag of records in
+	// separate fields, so the target only needs to be parsed for records
+	// written with all of their data in the target.
+	switch linodeRecord.Type {
+	case linodego.RecordTypeA, linodego.RecordTypeAAAA:
 		if ip, err := netip.ParseAddr(data); err == nil {
 			return libdns.Address{
 				Name:         name,
@@ -155,108 +537,168 @@ func convertToLibdnsRecord(zone string, linodeRecord *linodego.DomainRecord) lib
 				ProviderData: providerData,
 			}
 		}
-	case "TXT":
+	case linodego.RecordTypeTXT:
 		return libdns.TXT{
 			Name:         name,
 			TTL:          ttl,
-			Text:         data,
+			Text:         canonicalTXT(data),
 			ProviderData: providerData,
 		}
-	case "CNAME":
+	case linodego.RecordTypeCNAME:
 		return libdns.CNAME{
 			Name:         name,
 			TTL:          ttl,
 			Target:       data,
 			ProviderData: providerData,
 		}
-	case "MX":
-		// Parse priority and target from data (format: "10 mail.example.com")
-		parts := strings.SplitN(data, " ", 2)
-		if len(parts) == 2 {
-			if preference, err := strconv.Atoi(parts[0]); err == nil {
-				return libdns.MX{
-					Name:         name,
-					TTL:          ttl,
-					Preference:   uint16(preference),
-					Target:       parts[1],
-					ProviderData: providerData,
-				}
+	case linodego.RecordTypeMX:
+		preference, target := linodeRecord.Priority, data
+		// Records written by earlier versions have the preference in the
+		// target (format: "10 mail.example.com").
+		if before, after, ok := strings.Cut(data, " "); ok {
+			n, err := strconv.Atoi(before)
+			if err != nil {
+				break
 			}
+			preference, target = n, after
+		}
+		return libdns.MX{
+			Name:         name,
+			TTL:          ttl,
+			Preference:   uint16(preference),
+			Target:       target,
+			ProviderData: providerData,
 		}
-	case "SRV":
+	case linodego.RecordTypeSRV:
+		priority, weight, port, target := linodeRecord.Priority, linodeRecord.Weight, linodeRecord.Port, data
 		// Parse SRV data (format: "priority weight port target")
-		parts := strings.Fields(data)
-		if len(parts) == 4 {
-			if priority, err1 := strconv.Atoi(parts[0]); err1 == nil {
-				if weight, err2 := strconv.Atoi(parts[1]); err2 == nil {
-					if port, err3 := strconv.Atoi(parts[2]); err3 == nil {
-						// Parse service and transport from the name (format: _service._transport.name)
-						service := ""
-						transport := ""
-						if strings.HasPrefix(name, "_") {
-							nameParts := strings.SplitN(name, ".", 3)
-							if len(nameParts) >= 2 {
-								service = strings.TrimPrefix(nameParts[0], "_")
-								transport = strings.TrimPrefix(nameParts[1], "_")
-								if len(nameParts) >= 3 {
-									name = nameParts[2]
-								} else {
-									name = ""
-								}
-							}
-						}
-						return libdns.SRV{
-							Service:      service,
-							Transport:    transport,
-							Name:         name,
-							TTL:          ttl,
-							Priority:     uint16(priority),
-							Weight:       uint16(weight),
-							Port:         uint16(port),
-							Target:       parts[3],
-							ProviderData: providerData,
-						}
-					}
-				}
+		if strings.IndexByte(data, ' ') >= 0 {
+			var ok bool
+			if priority, weight, port, target, ok = parseSRVTarget(data); !ok {
+				break
 			}
 		}
-	case "NS":
+		service, transport, name := srvServiceAndTransport(name, linodeRecord.Service, linodeRecord.Protocol)
+		return libdns.SRV{
+			Service:      service,
+			Transport:    transport,
+			Name:         name,
+			TTL:          ttl,
+			Priority:     uint16(priority),
+			Weight:       uint16(weight),
+			Port:         uint16(port),
+			Target:       target,
+			ProviderData: providerData,
+		}
+	case linodego.RecordTypeNS:
 		return libdns.NS{
 			Name:         name,
 			TTL:          ttl,
 			Target:       data,
 			ProviderData: providerData,
 		}
-	case "CAA":
+	case linodego.RecordTypePTR:
+		return PTR{
+			Name:         name,
+			TTL:          ttl,
+			Target:       data,
+			ProviderData: providerData,
+		}
+	case linodego.RecordTypeCAA:
+		// Linode does not store CAA flags, they are always 0.
+		if linodeRecord.Tag != nil && *linodeRecord.Tag != "" {
+			return libdns.CAA{
+				Name:         name,
+				TTL:          ttl,
+				Tag:          *linodeRecord.Tag,
+				Value:        caaValue(data),
+				ProviderData: providerData,
+			}
+		}
 		// Parse CAA data (format: "flags tag value")
-		parts := strings.SplitN(data, " ", 3)
-		if len(parts) == 3 {
-			if flags, err := strconv.Atoi(parts[0]); err == nil {
-				return libdns.CAA{
-					Name:         name,
-					TTL:          ttl,
-					Flags:        uint8(flags),
-					Tag:          parts[1],
-					Value:        parts[2],
-					ProviderData: providerData,
-				}
+		flagsStr, rest, ok := strings.Cut(data, " ")
+		if !ok {
+			break
+		}
+		tag, value, ok := strings.Cut(rest, " ")
+		if !ok {
+			break
+		}
+		if flags, err := strconv.Atoi(flagsStr); err == nil {
+			return libdns.CAA{
+				Name:         name,
+				TTL:          ttl,
+				Flags:        uint8(flags),
+				Tag:          tag,
+				Value:        caaValue(value),
+				ProviderData: providerData,
 			}
 		}
 	}
-	
+
 	// Fallback to generic RR if no specific type matched
 	return libdns.RR{
-		Name:         name,
-		TTL:          ttl,
-		Type:         recordType,
-		Data:         data,
+		Name: name,
+		TTL:  ttl,
+		Type: string(linodeRecord.Type),
+		Data: data,
+	}
+}
+
+// caaValue returns the value of a CAA record without the quotes of its
+// presentation format, which targets written with the whole record data in
+// them still have.
+func caaValue(value string) string {
+	if strs, ok := splitQuotedStrings(value); ok && len(strs) == 1 {
+		return strs[0]
+	}
+	return value
+}
+
+// parseSRVTarget parses an SRV target of the form "priority weight port target".
+func parseSRVTarget(data string) (priority, weight, port int, target string, ok bool) {
+	var fields [3]int
+	rest := strings.TrimSpace(data)
+	for i := range fields {
+		var field string
+		if field, rest, ok = strings.Cut(rest, " "); !ok {
+			return 0, 0, 0, "", false
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return 0, 0, 0, "", false
+		}
+		fields[i] = n
+		rest = strings.TrimLeft(rest, " ")
+	}
+	if rest == "" || strings.IndexByte(rest, ' ') >= 0 {
+		return 0, 0, 0, "", false
 	}
+	return fields[0], fields[1], fields[2], rest, true
+}
+
+// srvServiceAndTransport returns the service, transport and remaining name of
+// an SRV record. Linode returns the service and protocol in separate fields;
+// the name may still start with them (format: _service._transport.name).
+func srvServiceAndTransport(name string, service, protocol *string) (string, string, string) {
+	var svc, transport string
+	if first, rest, ok := strings.Cut(name, "."); ok && strings.HasPrefix(first, "_") {
+		second, rest, _ := strings.Cut(rest, ".")
+		svc, transport, name = strings.TrimPrefix(first, "_"), strings.TrimPrefix(second, "_"), rest
+	}
+	if service != nil && *service != "" {
+		svc = strings.TrimPrefix(*service, "_")
+	}
+	if protocol != nil && *protocol != "" {
+		transport = strings.TrimPrefix(*protocol, "_")
+	}
+	return svc, transport, name
 }
 
 func mergeWithExistingLibdnsRecord(zone string, existingRecord libdns.Record, linodeRecord *linodego.DomainRecord) libdns.Record {
 	// Create a new record based on the Linode record data
 	newRecord := convertToLibdnsRecord(zone, linodeRecord)
-	
+
 	// If the existing record has the same type, try to preserve any non-provider data
 	if existingRecord != nil {
 		existingRR := existingRecord.RR()
@@ -267,55 +709,152 @@ func mergeWithExistingLibdnsRecord(zone string, existingRecord libdns.Record, li
 			return newRecord
 		}
 	}
-	
+
 	return newRecord
 }
 
-// Helper function to extract provider data from a record
-func getProviderData(record libdns.Record) (map[string]interface{}, bool) {
+// recordData is stored in the ProviderData of records read from Linode.
+type recordData struct {
+	ID int
+}
+
+// withRecordID returns the record with ProviderData identifying the Linode
+// record with the given ID. Records of other types are returned unchanged.
+func withRecordID(record libdns.Record, id int) libdns.Record {
+	data := recordData{ID: id}
 	switch r := record.(type) {
 	case libdns.Address:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.TXT:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.CNAME:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.MX:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.SRV:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.NS:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
-			}
-		}
+		r.ProviderData = data
+		return r
 	case libdns.CAA:
-		if r.ProviderData != nil {
-			if data, ok := r.ProviderData.(map[string]interface{}); ok {
-				return data, true
+		r.ProviderData = data
+		return r
+	case PTR:
+		r.ProviderData = data
+		return r
+	}
+	return record
+}
+
+// withTTL returns the record with its TTL replaced.
+func withTTL(record libdns.Record, ttl time.Duration) libdns.Record {
+	switch r := record.(type) {
+	case libdns.Address:
+		r.TTL = ttl
+		return r
+	case libdns.TXT:
+		r.TTL = ttl
+		return r
+	case libdns.CNAME:
+		r.TTL = ttl
+		return r
+	case libdns.MX:
+		r.TTL = ttl
+		return r
+	case libdns.SRV:
+		r.TTL = ttl
+		return r
+	case libdns.NS:
+		r.TTL = ttl
+		return r
+	case libdns.CAA:
+		r.TTL = ttl
+		return r
+	case PTR:
+		r.TTL = ttl
+		return r
+	case libdns.RR:
+		r.TTL = ttl
+		return r
+	}
+	return record
+}
+
+// recordID returns the Linode ID of a record read from Linode. The provider
+// data of earlier versions, map[string]interface{}{"id": "<id>"}, is accepted
+// as well.
+func recordID(record libdns.Record) (int, bool) {
+	var data interface{}
+	switch r := record.(type) {
+	case libdns.Address:
+		data = r.ProviderData
+	case libdns.TXT:
+		data = r.ProviderData
+	case libdns.CNAME:
+		data = r.ProviderData
+	case libdns.MX:
+		data = r.ProviderData
+	case libdns.SRV:
+		data = r.ProviderData
+	case libdns.NS:
+		data = r.ProviderData
+	case libdns.CAA:
+		data = r.ProviderData
+	case PTR:
+		data = r.ProviderData
+	}
+	switch data := data.(type) {
+	case recordData:
+		return data.ID, true
+	case map[string]interface{}:
+		if id, ok := data["id"].(string); ok {
+			if n, err := strconv.Atoi(id); err == nil {
+				return n, true
 			}
 		}
 	}
-	return nil, false
+	return 0, false
+}
+
+// validateRecords checks that every record can be stored by Linode before any
+// API request is made.
+func validateRecords(zone string, records []libdns.Record) error {
+	for i, record := range records {
+		if err := validateRecord(zone, record); err != nil {
+			return &RecordError{Index: i, Record: record, Err: err}
+		}
+	}
+	return nil
+}
+
+func validateRecord(zone string, record libdns.Record) error {
+	rr := record.RR()
+	if !isSupportedRecordType(rr.Type) {
+		return unsupportedTypeError(rr.Type)
+	}
+	name := relativeName(rr.Name, zone)
+	if len(name) > maxRecordNameLength {
+		return fmt.Errorf("%w: name is %d bytes, Linode allows at most %d", ErrInvalidRecord, len(name), maxRecordNameLength)
+	}
+	fqdn := strings.TrimSuffix(libdns.AbsoluteName(name, zone), ".")
+	// The wire format of a name is each label prefixed by its length, plus the root label.
+	wireLength := 1
+	for _, label := range strings.Split(fqdn, ".") {
+		if len(label) > maxLabelLength {
+			return fmt.Errorf("%w: label %q is %d bytes, DNS allows at most %d", ErrInvalidRecord, label, len(label), maxLabelLength)
+		}
+		wireLength += len(label) + 1
+	}
+	if wireLength > maxDomainNameLength {
+		return fmt.Errorf("%w: name %q is %d bytes, DNS allows at most %d", ErrInvalidRecord, fqdn, wireLength, maxDomainNameLength)
+	}
+	if len(rr.Data) > maxRecordTargetLength {
+		return fmt.Errorf("%w: target is %d bytes, Linode allows at most %d", ErrInvalidRecord, len(rr.Data), maxRecordTargetLength)
+	}
+	return nil
 }
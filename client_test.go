@@ -0,0 +1,152 @@
+package linode_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/linode/linodego"
+)
+
+// assertNoOp sets the records again and fails the test unless nothing
+// had to be written.
+func assertNoOp(t *testing.T, provider *linode.Provider, records []libdns.Record) {
+	t.Helper()
+	results, err := provider.SetRecordsWithResults(context.Background(), testZone, records)
+	if err != nil {
+		t.Fatalf("SetRecords: %v", err)
+	}
+	for _, result := range results {
+		if result.Outcome != linode.OutcomeNoOp {
+			rr := result.Record.RR()
+			t.Errorf("setting %s %s %q again: %s, want no-op", rr.Name, rr.Type, rr.Data, result.Outcome)
+		}
+	}
+}
+
+func TestCAARoundTrip(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	caa := libdns.CAA{Name: "@", Tag: "issue", Value: "letsencrypt.org"}
+	mustAppend(t, provider, caa)
+
+	stored := fake.Records(domainID)
+	if len(stored) != 1 || stored[0].Tag == nil || *stored[0].Tag != "issue" || stored[0].Target != "letsencrypt.org" {
+		t.Fatalf("stored %+v, want tag issue and target letsencrypt.org", stored)
+	}
+	records := mustGet(t, provider)
+	if got, ok := records[0].(libdns.CAA); !ok || got.Tag != caa.Tag || got.Value != caa.Value {
+		t.Fatalf("read %#v, want %v", records[0], caa)
+	}
+	assertNoOp(t, provider, records)
+	assertNoOp(t, provider, []libdns.Record{caa})
+}
+
+func TestCAAReadsQuotedTargets(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	tag := "issue"
+	for _, opts := range []linodego.DomainRecordCreateOptions{
+		{Type: linodego.RecordTypeCAA, Target: `0 issuewild "letsencrypt.org"`},
+		{Type: linodego.RecordTypeCAA, Target: `"pki.goog"`, Tag: &tag},
+	} {
+		if _, err := fake.CreateDomainRecord(context.Background(), domainID, opts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []libdns.CAA{{Tag: "issuewild", Value: "letsencrypt.org"}, {Tag: "issue", Value: "pki.goog"}}
+	records := mustGet(t, provider)
+	for i, record := range records {
+		got, ok := record.(libdns.CAA)
+		if !ok || got.Tag != want[i].Tag || got.Value != want[i].Value {
+			t.Errorf("record %d = %#v, want tag %s value %s", i, record, want[i].Tag, want[i].Value)
+		}
+	}
+}
+
+func TestTXTRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		// data is the TXT data written, and text the text read back.
+		data, text string
+	}{
+		{"plain", "v=spf1 -all", "v=spf1 -all"},
+		{"quoted", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"several strings", `"v=DKIM1; k=rsa; " "p=MIGf"`, "v=DKIM1; k=rsa; p=MIGf"},
+		{"long", strings.Repeat("k", 600), strings.Repeat("k", 600)},
+		{"quotes inside", `say "hi"`, `say "hi"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, _, _ := newTestProvider(t)
+			written := libdns.RR{Name: "txt", Type: "TXT", Data: test.data}
+			mustAppend(t, provider, written)
+			records := mustGet(t, provider)
+			txt, ok := records[0].(libdns.TXT)
+			if !ok || txt.Text != test.text {
+				t.Fatalf("read %#v, want text %q", records[0], test.text)
+			}
+			assertNoOp(t, provider, records)
+			assertNoOp(t, provider, []libdns.Record{written})
+		})
+	}
+}
+
+func TestMXRoundTrip(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	mx := libdns.MX{Name: "@", Preference: 10, Target: "mail.example.com."}
+	mustAppend(t, provider, mx)
+
+	stored := fake.Records(domainID)
+	if len(stored) != 1 || stored[0].Priority != 10 || stored[0].Target != "mail.example.com." {
+		t.Fatalf("stored %+v, want priority 10 and target mail.example.com.", stored)
+	}
+	records := mustGet(t, provider)
+	if got, ok := records[0].(libdns.MX); !ok || got.Preference != 10 || got.Target != "mail.example.com." {
+		t.Fatalf("read %#v, want preference 10 and target mail.example.com.", records[0])
+	}
+	assertNoOp(t, provider, records)
+
+	mx.Preference = 20
+	mustSet(t, provider, mx)
+	if stored := fake.Records(domainID); len(stored) != 1 || stored[0].Priority != 20 {
+		t.Fatalf("stored %+v after changing the preference, want priority 20", stored)
+	}
+}
+
+func TestMXReadsPreferenceInTarget(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	opts := linodego.DomainRecordCreateOptions{Type: linodego.RecordTypeMX, Target: "5 mail.example.com."}
+	if _, err := fake.CreateDomainRecord(context.Background(), domainID, opts); err != nil {
+		t.Fatal(err)
+	}
+	records := mustGet(t, provider)
+	if got, ok := records[0].(libdns.MX); !ok || got.Preference != 5 || got.Target != "mail.example.com." {
+		t.Fatalf("read %#v, want preference 5 and target mail.example.com.", records[0])
+	}
+}
+
+func TestSRVRoundTrip(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	srv := libdns.SRV{Service: "sip", Transport: "tcp", Name: "@", TTL: time.Hour, Priority: 10, Weight: 60, Port: 5060, Target: "sip.example.com."}
+	mustAppend(t, provider, srv)
+
+	stored := fake.Records(domainID)
+	if len(stored) != 1 || stored[0].Priority != 10 || stored[0].Weight != 60 || stored[0].Port != 5060 ||
+		stored[0].Service == nil || *stored[0].Service != "sip" || stored[0].Protocol == nil || *stored[0].Protocol != "tcp" {
+		t.Fatalf("stored %+v, want service sip, protocol tcp, priority 10, weight 60 and port 5060", stored)
+	}
+	records := mustGet(t, provider)
+	got, ok := records[0].(libdns.SRV)
+	if !ok || got.Service != "sip" || got.Transport != "tcp" || got.Priority != 10 || got.Weight != 60 || got.Port != 5060 || got.Target != "sip.example.com." {
+		t.Fatalf("read %#v, want %v", records[0], srv)
+	}
+	assertNoOp(t, provider, records)
+
+	srv.Weight, srv.Port = 30, 5061
+	mustSet(t, provider, srv)
+	if stored := fake.Records(domainID); len(stored) != 1 || stored[0].Weight != 30 || stored[0].Port != 5061 {
+		t.Fatalf("stored %+v after changing weight and port, want weight 30 and port 5061", stored)
+	}
+}
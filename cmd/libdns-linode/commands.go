@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+// command is a command for managing zones from the command line.
+type command struct {
+	usage string
+	run   func(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error
+}
+
+var commands = map[string]command{
+	"list":   {"list <zone>", runList},
+	"diff":   {"diff <zone> <file>", runDiff},
+	"plan":   {"plan [-prune] <zone> <file>", runPlan},
+	"apply":  {"apply [-dry-run] [-prune] <zone> <file|->", runApply},
+	"doctor": {"doctor <zone>", runDoctor},
+}
+
+// stdin is read by commands given "-" as file.
+var stdin io.Reader = os.Stdin
+
+// runCommand runs the command named by the first argument with the provider.
+func runCommand(provider *linode.Provider, out output, args []string) error {
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: libdns-linode [-json] %s\n", cmd.usage)
+		flags.PrintDefaults()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	return cmd.run(ctx, provider, out, flags, args[1:])
+}
+
+// parseArgs parses the flags of a command and checks that n arguments remain.
+func parseArgs(flags *flag.FlagSet, args []string, n int) ([]string, error) {
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() != n {
+		flags.Usage()
+		return nil, errors.New("wrong number of arguments")
+	}
+	return flags.Args(), nil
+}
+
+func runList(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	records, err := provider.GetRecords(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return out.records(records)
+}
+
+func runDiff(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 2)
+	if err != nil {
+		return err
+	}
+	desired, err := readRecordsFile(args[1], args[0])
+	if err != nil {
+		return err
+	}
+	monitor := &linode.DriftMonitor{
+		Provider: provider,
+		Desired: func(context.Context, string) ([]libdns.Record, error) {
+			return desired, nil
+		},
+	}
+	drift, err := monitor.Check(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return out.drift(drift)
+}
+
+func runPlan(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error {
+	prune := flags.Bool("prune", false, "delete the records of RRsets that are not in the file")
+	args, err := parseArgs(flags, args, 2)
+	if err != nil {
+		return err
+	}
+	desired, err := readRecordsFile(args[1], args[0])
+	if err != nil {
+		return err
+	}
+	results, err := provider.SyncZone(linode.WithDryRun(ctx), args[0], desired, linode.SyncOptions{Prune: *prune})
+	if err != nil {
+		return err
+	}
+	return out.results(results)
+}
+
+func runApply(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error {
+	dryRun := flags.Bool("dry-run", false, "only show the writes that would be made")
+	prune := flags.Bool("prune", false, "delete the records of RRsets that are not in the file")
+	args, err := parseArgs(flags, args, 2)
+	if err != nil {
+		return err
+	}
+	desired, err := readRecordsFile(args[1], args[0])
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		ctx = linode.WithDryRun(ctx)
+	}
+	results, err := provider.SyncZone(ctx, args[0], desired, linode.SyncOptions{Prune: *prune})
+	if results != nil {
+		if err := out.results(results); err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// readRecordsFile reads the records of the zone from a file, or from standard
+// input if path is "-", see readRecords.
+func readRecordsFile(path, zone string) ([]libdns.Record, error) {
+	if path == "-" {
+		return readRecords(stdin, zone)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readRecords(f, zone)
+}
+
+// readRecords reads records in the zone file format, or as a JSON array of
+// records if the input starts with "[".
+func readRecords(r io.Reader, zone string) ([]libdns.Record, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return linode.ParseZoneFile(bytes.NewReader(data), zone)
+	}
+	var rrs []libdns.RR
+	if err := json.Unmarshal(data, &rrs); err != nil {
+		return nil, fmt.Errorf("could not parse records: %v", err)
+	}
+	records := make([]libdns.Record, len(rrs))
+	for i, rr := range rrs {
+		if records[i], err = rr.Parse(); err != nil {
+			return nil, fmt.Errorf("could not parse record %s %s: %v", rr.Name, rr.Type, err)
+		}
+	}
+	return records, nil
+}
+
+// output prints the output of commands as tables, or as JSON.
+type output struct {
+	w    io.Writer
+	json bool
+}
+
+// resultJSON is the JSON form of a linode.RecordResult.
+type resultJSON struct {
+	libdns.RR
+	Outcome linode.Outcome `json:"outcome"`
+	Error   string         `json:"error,omitempty"`
+}
+
+func (o output) encode(v any) error {
+	encoder := json.NewEncoder(o.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// table prints rows of tab-separated cells as aligned columns.
+func (o output) table(rows []string) error {
+	w := tabwriter.NewWriter(o.w, 0, 8, 2, ' ', 0)
+	for _, row := range rows {
+		fmt.Fprintln(w, row)
+	}
+	return w.Flush()
+}
+
+func (o output) records(records []libdns.Record) error {
+	rrs := rrsOf(records)
+	if o.json {
+		return o.encode(rrs)
+	}
+	rows := []string{"NAME\tTTL\tTYPE\tDATA"}
+	for _, rr := range rrs {
+		rows = append(rows, formatRR(rr))
+	}
+	return o.table(rows)
+}
+
+func (o output) drift(drift linode.Drift) error {
+	if o.json {
+		return o.encode(struct {
+			Missing    []libdns.RR `json:"missing"`
+			Unexpected []libdns.RR `json:"unexpected"`
+		}{rrsOf(drift.Missing), rrsOf(drift.Unexpected)})
+	}
+	var rows []string
+	for _, rr := range rrsOf(drift.Missing) {
+		rows = append(rows, "+\t"+formatRR(rr))
+	}
+	for _, rr := range rrsOf(drift.Unexpected) {
+		rows = append(rows, "-\t"+formatRR(rr))
+	}
+	return o.table(rows)
+}
+
+func (o output) results(results []linode.RecordResult) error {
+	converted := make([]resultJSON, len(results))
+	for i, result := range results {
+		converted[i] = resultJSON{RR: result.Record.RR(), Outcome: result.Outcome}
+		if result.Err != nil {
+			converted[i].Error = result.Err.Error()
+		}
+	}
+	if o.json {
+		return o.encode(converted)
+	}
+	rows := []string{"OUTCOME\tNAME\tTTL\tTYPE\tDATA"}
+	for _, result := range converted {
+		row := string(result.Outcome) + "\t" + formatRR(result.RR)
+		if result.Error != "" {
+			row += "\t" + result.Error
+		}
+		rows = append(rows, row)
+	}
+	return o.table(rows)
+}
+
+// findings prints the findings of the doctor command, and returns an error if
+// any check failed.
+func (o output) findings(findings []finding) error {
+	var err error
+	if o.json {
+		err = o.encode(findings)
+	} else {
+		var rows []string
+		for _, f := range findings {
+			status := "ok"
+			if !f.OK {
+				status = "PROBLEM"
+			}
+			rows = append(rows, status+"\t"+f.Check+"\t"+f.Detail)
+		}
+		err = o.table(rows)
+	}
+	if err != nil {
+		return err
+	}
+	problems := 0
+	for _, f := range findings {
+		if !f.OK {
+			problems++
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("found %d problems", problems)
+	}
+	return nil
+}
+
+// rrsOf returns the RRs of the records, which never is nil, so that it
+// encodes as an empty JSON array.
+func rrsOf(records []libdns.Record) []libdns.RR {
+	rrs := make([]libdns.RR, len(records))
+	for i, record := range records {
+		rrs[i] = record.RR()
+	}
+	return rrs
+}
+
+func formatRR(rr libdns.RR) string {
+	return strings.Join([]string{rr.Name, rr.TTL.String(), rr.Type, rr.Data}, "\t")
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// newTestProvider returns a provider backed by a Fake with the domain
+// example.com holding the records.
+func newTestProvider(t *testing.T, records ...libdns.Record) *linode.Provider {
+	t.Helper()
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: fake}
+	if _, err := provider.AppendRecords(context.Background(), "example.com.", records); err != nil {
+		t.Fatal(err)
+	}
+	return provider
+}
+
+// run runs the command and returns its output.
+func run(t *testing.T, provider *linode.Provider, jsonOutput bool, args ...string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := runCommand(provider, output{w: &b, json: jsonOutput}, args); err != nil {
+		t.Fatalf("%s: %v", args[0], err)
+	}
+	return b.String()
+}
+
+// writeFile writes the contents to a file in a temporary directory and
+// returns its path.
+func writeFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "records")
+	if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestListJSON(t *testing.T) {
+	provider := newTestProvider(t, libdns.TXT{Name: "www", Text: "hello"})
+	var rrs []libdns.RR
+	if err := json.Unmarshal([]byte(run(t, provider, true, "list", "example.com.")), &rrs); err != nil {
+		t.Fatal(err)
+	}
+	if len(rrs) != 1 || rrs[0] != (libdns.RR{Name: "www", Type: "TXT", Data: "hello"}) {
+		t.Errorf("list = %+v, want www TXT hello", rrs)
+	}
+}
+
+func TestListTable(t *testing.T) {
+	provider := newTestProvider(t, libdns.TXT{Name: "www", Text: "hello"})
+	out := run(t, provider, false, "list", "example.com.")
+	if !strings.HasPrefix(out, "NAME") || !strings.Contains(out, "www") || !strings.Contains(out, "hello") {
+		t.Errorf("list = %q, want a table with www", out)
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	provider := newTestProvider(t, libdns.TXT{Name: "old", Text: "old"})
+	path := writeFile(t, `[{"name": "new", "type": "TXT", "data": "new"}]`)
+	var drift struct {
+		Missing    []libdns.RR `json:"missing"`
+		Unexpected []libdns.RR `json:"unexpected"`
+	}
+	if err := json.Unmarshal([]byte(run(t, provider, true, "diff", "example.com.", path)), &drift); err != nil {
+		t.Fatal(err)
+	}
+	if len(drift.Missing) != 1 || drift.Missing[0].Name != "new" || len(drift.Unexpected) != 1 || drift.Unexpected[0].Name != "old" {
+		t.Errorf("diff = %+v, want new missing and old unexpected", drift)
+	}
+}
+
+func TestPlanJSON(t *testing.T) {
+	provider := newTestProvider(t, libdns.TXT{Name: "old", Text: "old"})
+	path := writeFile(t, "new 300 IN TXT \"new\"\n")
+	var results []resultJSON
+	if err := json.Unmarshal([]byte(run(t, provider, true, "plan", "-prune", "example.com.", path)), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Outcome != linode.OutcomeCreated || results[1].Outcome != linode.OutcomeDeleted {
+		t.Fatalf("plan = %+v, want new created and old deleted", results)
+	}
+	if records, _ := provider.GetRecords(context.Background(), "example.com."); len(records) != 1 || records[0].RR().Name != "old" {
+		t.Errorf("plan changed the zone to %v", records)
+	}
+}
+
+func TestApplyFromStdin(t *testing.T) {
+	provider := newTestProvider(t, libdns.TXT{Name: "www", Text: "old"})
+	defer func(r io.Reader) { stdin = r }(stdin)
+	records := `[{"name": "www", "type": "TXT", "data": "new"}, {"name": "mail", "type": "A", "data": "192.0.2.1"}]`
+
+	stdin = strings.NewReader(records)
+	run(t, provider, false, "apply", "-dry-run", "example.com.", "-")
+	if got, _ := provider.GetRecords(context.Background(), "example.com."); len(got) != 1 || got[0].RR().Data != "old" {
+		t.Fatalf("the dry run changed the zone to %v", got)
+	}
+
+	stdin = strings.NewReader(records)
+	var results []resultJSON
+	if err := json.Unmarshal([]byte(run(t, provider, true, "apply", "example.com.", "-")), &results); err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Outcome != linode.OutcomeUpdated || results[1].Outcome != linode.OutcomeCreated {
+		t.Errorf("apply = %+v, want www updated and mail created", results)
+	}
+	got, _ := provider.GetRecords(context.Background(), "example.com.")
+	if len(got) != 2 {
+		t.Errorf("records after apply = %v, want www and mail", got)
+	}
+}
+
+// stubLookupNS makes the doctor command see the zones delegated to the
+// nameservers.
+func stubLookupNS(t *testing.T, nameservers ...string) {
+	t.Helper()
+	saved := lookupNS
+	t.Cleanup(func() { lookupNS = saved })
+	lookupNS = func(context.Context, string) ([]*net.NS, error) {
+		var ns []*net.NS
+		for _, host := range nameservers {
+			ns = append(ns, &net.NS{Host: host})
+		}
+		return ns, nil
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	stubLookupNS(t, "ns1.linode.com.", "ns2.linode.com.")
+	provider := newTestProvider(t, libdns.TXT{Name: "www", Text: "hello"})
+	var findings []finding
+	if err := json.Unmarshal([]byte(run(t, provider, true, "doctor", "example.com.")), &findings); err != nil {
+		t.Fatal(err)
+	}
+	checks := map[string]bool{}
+	for _, f := range findings {
+		if !f.OK {
+			t.Errorf("%s: %s", f.Check, f.Detail)
+		}
+		checks[f.Check] = true
+	}
+	for _, check := range []string{"configuration", "token", "zone", "latency", "grants", "delegation"} {
+		if !checks[check] {
+			t.Errorf("no %s finding in %+v", check, findings)
+		}
+	}
+}
+
+func TestDoctorFindsProblems(t *testing.T) {
+	stubLookupNS(t, "ns1.example.net.")
+	provider := newTestProvider(t)
+	var b bytes.Buffer
+	err := runCommand(provider, output{w: &b}, []string{"doctor", "example.org."})
+	if err == nil || err.Error() != "found 2 problems" {
+		t.Errorf("doctor = %v, want 2 problems", err)
+	}
+	problems := map[string]bool{}
+	for _, line := range strings.Split(b.String(), "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "PROBLEM" {
+			problems[fields[1]] = true
+		}
+	}
+	if !problems["zone"] || !problems["delegation"] || !strings.Contains(b.String(), "ns1.example.net") {
+		t.Errorf("doctor = %q, want problems with the zone and its delegation to ns1.example.net", b.String())
+	}
+}
+
+// readOnlyAPI fails the test on every write.
+type readOnlyAPI struct {
+	*linodetest.Fake
+	t *testing.T
+}
+
+func (a readOnlyAPI) UpdateDomain(context.Context, int, linodego.DomainUpdateOptions) (*linodego.Domain, error) {
+	a.t.Error("doctor updated the domain")
+	return nil, errors.New("read-only")
+}
+
+func (a readOnlyAPI) CreateDomainRecord(context.Context, int, linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error) {
+	a.t.Error("doctor created a record")
+	return nil, errors.New("read-only")
+}
+
+func (a readOnlyAPI) UpdateDomainRecord(context.Context, int, int, linodego.DomainRecordUpdateOptions) (*linodego.DomainRecord, error) {
+	a.t.Error("doctor updated a record")
+	return nil, errors.New("read-only")
+}
+
+func (a readOnlyAPI) DeleteDomainRecord(context.Context, int, int) error {
+	a.t.Error("doctor deleted a record")
+	return errors.New("read-only")
+}
+
+func TestDoctorReadOnlyGrant(t *testing.T) {
+	stubLookupNS(t, "ns1.linode.com.")
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("example.com")
+	fake.Grants = &linodego.UserGrants{Domain: []linodego.GrantedEntity{{ID: domainID, Permissions: linodego.AccessLevelReadOnly}}}
+	provider := &linode.Provider{API: readOnlyAPI{Fake: fake, t: t}}
+	var b bytes.Buffer
+	err := runCommand(provider, output{w: &b, json: true}, []string{"doctor", "example.com."})
+	if err == nil || err.Error() != "found 1 problems" {
+		t.Errorf("doctor = %v, want 1 problem", err)
+	}
+	var findings []finding
+	if err := json.Unmarshal(b.Bytes(), &findings); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range findings {
+		if f.Check == "grants" && f.OK {
+			t.Errorf("grants finding = %+v, want a problem", f)
+		}
+	}
+}
+
+func TestCanWriteDomains(t *testing.T) {
+	for scopes, want := range map[string]bool{
+		"*":                                     true,
+		"domains:read_write":                    true,
+		"events:read_only domains:read_write":   true,
+		"linodes:read_write,domains:read_write": true,
+		"domains:read_only":                     false,
+		"":                                      false,
+	} {
+		if got := canWriteDomains(scopes); got != want {
+			t.Errorf("canWriteDomains(%q) = %v, want %v", scopes, got, want)
+		}
+	}
+}
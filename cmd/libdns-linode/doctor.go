@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/libdns/linode"
+	"github.com/linode/linodego"
+)
+
+// lookupNS looks up the nameservers a zone is delegated to.
+var lookupNS = net.DefaultResolver.LookupNS
+
+// finding is the result of one check of the doctor command.
+type finding struct {
+	Check  string `json:"check"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+// runDoctor checks what commonly keeps the provider from managing a zone, and
+// prints a finding for every check, to attach to support requests.
+func runDoctor(ctx context.Context, provider *linode.Provider, out output, flags *flag.FlagSet, args []string) error {
+	args, err := parseArgs(flags, args, 1)
+	if err != nil {
+		return err
+	}
+	zone := args[0]
+	headers := &responseHeaders{}
+	if provider.HTTPClient == nil {
+		provider.HTTPClient = &http.Client{Transport: headers}
+	}
+	var findings []finding
+	report := func(check string, ok bool, format string, a ...any) {
+		findings = append(findings, finding{Check: check, OK: ok, Detail: fmt.Sprintf(format, a...)})
+	}
+
+	if err := provider.Validate(); err != nil {
+		report("configuration", false, "%v", err)
+		return out.findings(findings)
+	}
+	report("configuration", true, "valid")
+
+	start := time.Now()
+	records, err := provider.GetRecords(ctx, zone)
+	latency := time.Since(start)
+	var apiErr *linodego.Error
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusUnauthorized:
+		report("token", false, "the API token is invalid or expired: %v", err)
+	case errors.As(err, &apiErr) && apiErr.Code == http.StatusForbidden:
+		report("token", false, "the API token lacks the Domains scope: %v", err)
+	case errors.Is(err, linode.ErrZoneNotFound):
+		report("token", true, "the API token can read domains")
+		report("zone", false, "no Linode domain matches %s; create it in Cloud Manager or with CreateZone", zone)
+	case err != nil:
+		report("api", false, "could not read the zone: %v", err)
+	default:
+		report("token", true, "the API token can read domains")
+		report("zone", true, "%s has %d records", zone, len(records))
+	}
+	if err == nil {
+		report("latency", latency < 2*time.Second, "reading the zone took %s", latency.Round(time.Millisecond))
+		// Doctor only reads: write access is checked from the token's
+		// scopes and its user's grants, not by writing to the zone.
+		if scopes, ok := headers.oauthScopes(); ok {
+			if canWriteDomains(scopes) {
+				report("scope", true, "the API token's scopes allow writing domains")
+			} else {
+				report("scope", false, "the API token's scopes are %q; it needs domains:read_write", scopes)
+			}
+		}
+		writable, err := provider.ZoneWritable(ctx, zone)
+		switch {
+		case err != nil:
+			report("grants", false, "could not check write access: %v", err)
+		case !writable:
+			report("grants", false, "the user of the API token has no read/write grant for %s", zone)
+		default:
+			report("grants", true, "the user of the API token may write %s", zone)
+		}
+	}
+
+	nameservers, err := lookupNS(ctx, strings.TrimSuffix(zone, "."))
+	if err != nil {
+		report("delegation", false, "could not look up the nameservers of %s: %v", zone, err)
+	} else {
+		var other []string
+		for _, ns := range nameservers {
+			if host := strings.ToLower(strings.TrimSuffix(ns.Host, ".")); !strings.HasSuffix(host, ".linode.com") {
+				other = append(other, host)
+			}
+		}
+		if len(other) > 0 || len(nameservers) == 0 {
+			report("delegation", false, "%s is delegated to %s, not to Linode's nameservers ns1.linode.com to ns5.linode.com", zone, strings.Join(other, ", "))
+		} else {
+			report("delegation", true, "%s is delegated to Linode", zone)
+		}
+	}
+
+	if limit, remaining, ok := headers.rateLimit(); ok {
+		report("rate limit", remaining*10 >= limit, "%d of %d requests left in the current window", remaining, limit)
+	}
+	return out.findings(findings)
+}
+
+// canWriteDomains reports whether the OAuth scopes of a token, as listed by
+// the X-OAuth-Scopes header, allow writing domains.
+func canWriteDomains(scopes string) bool {
+	for _, scope := range strings.FieldsFunc(scopes, func(r rune) bool { return r == ' ' || r == ',' }) {
+		if scope == "*" || scope == "domains:read_write" {
+			return true
+		}
+	}
+	return false
+}
+
+// responseHeaders is an HTTP transport that keeps the rate limit and OAuth
+// scope headers of the last Linode API response.
+type responseHeaders struct {
+	mutex            sync.Mutex
+	limit, remaining int
+	seenLimit        bool
+	scopes           string
+	seenScopes       bool
+}
+
+func (h *responseHeaders) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	var limit, remaining int
+	_, limitErr := fmt.Sscan(resp.Header.Get("X-RateLimit-Limit"), &limit)
+	_, remainingErr := fmt.Sscan(resp.Header.Get("X-RateLimit-Remaining"), &remaining)
+	if limitErr == nil && remainingErr == nil {
+		h.limit, h.remaining, h.seenLimit = limit, remaining, true
+	}
+	if scopes, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		h.scopes, h.seenScopes = strings.Join(scopes, " "), true
+	}
+	return resp, nil
+}
+
+// rateLimit returns the rate limit and the requests remaining, if a response
+// had them.
+func (h *responseHeaders) rateLimit() (limit, remaining int, ok bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.limit, h.remaining, h.seenLimit
+}
+
+// oauthScopes returns the OAuth scopes of the token, if a response had them.
+func (h *responseHeaders) oauthScopes() (string, bool) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.scopes, h.seenScopes
+}
@@ -0,0 +1,62 @@
+// Command libdns-linode manages Linode DNS zones from the command line with
+// the provider:
+//
+//	libdns-linode list <zone>          lists the records of the zone
+//	libdns-linode diff <zone> <file>   compares the zone with the records of the file
+//	libdns-linode plan <zone> <file>   shows the writes that would make the zone match the file
+//	libdns-linode apply <zone> <file>  makes the zone match the file, see linode.Provider.SyncZone
+//	libdns-linode doctor <zone>        checks the token, its scopes and grants, the zone, its
+//	                                   delegation to Linode, the API latency and the rate limit
+//	                                   headroom, without writing anything
+//
+// The Linode API token is read from the LINODE_TOKEN environment variable.
+// Plan and apply delete the records of other RRsets with -prune, and apply
+// only shows what it would do with -dry-run. Given "-" as file, apply reads
+// the records from standard input, so that they can come from a generator:
+//
+//	generate-records | libdns-linode apply -dry-run example.com -
+//
+// Files hold records in the zone file format, or as a JSON array of records
+// with name, ttl (in nanoseconds), type and data. With -json, the commands
+// print JSON instead of tables, for jq and scripts:
+//
+//	libdns-linode -json list example.com | jq -r '.[].name'
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/libdns/linode"
+)
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "print the output of commands as JSON")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: libdns-linode [-json] <command> [arguments]\n\ncommands:")
+		var usages []string
+		for _, cmd := range commands {
+			usages = append(usages, cmd.usage)
+		}
+		sort.Strings(usages)
+		for _, usage := range usages {
+			fmt.Fprintln(flag.CommandLine.Output(), "\t"+usage)
+		}
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	provider := &linode.Provider{}
+	err := runCommand(provider, output{w: os.Stdout, json: *jsonOutput}, flag.Args())
+	provider.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
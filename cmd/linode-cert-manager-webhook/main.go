@@ -0,0 +1,81 @@
+// Command linode-cert-manager-webhook is a cert-manager DNS01 webhook solver
+// for Linode DNS. It solves DNS-01 challenges of Issuers and ClusterIssuers
+// by creating and deleting the challenge TXT records through the Linode API.
+//
+// cert-manager reaches webhook solvers through the Kubernetes API server, so
+// the command serves the webhook's API group over HTTPS, to be registered with
+// an APIService. The group is read from the GROUP_NAME environment variable,
+// as with other solvers, and the solver is named "linode":
+//
+//	solvers:
+//	- dns01:
+//	    webhook:
+//	      groupName: acme.example.com
+//	      solverName: linode
+//
+// The Linode API token is read from the LINODE_TOKEN environment variable,
+// typically from a Secret. Only requests with a client certificate issued by
+// the -client-ca CA are served, which should be the CA the API server
+// authenticates to aggregated API servers with (requestheader-client-ca-file).
+// Whatever the caller, only TXT records named _acme-challenge are written.
+// Since every connection needs a client certificate, probes should use
+// tcpSocket rather than /healthz.
+//
+//	GROUP_NAME=acme.example.com LINODE_TOKEN=... linode-cert-manager-webhook -tls-cert tls.crt -tls-key tls.key -client-ca ca.crt
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	listen := flag.String("listen", ":443", "address to listen on")
+	certFile := flag.String("tls-cert", "", "file with the TLS certificate")
+	keyFile := flag.String("tls-key", "", "file with the TLS key")
+	clientCAFile := flag.String("client-ca", "", "file with the CA of the client certificates to require")
+	timeout := flag.Duration("timeout", time.Minute, "timeout for presenting or cleaning up one challenge")
+	flag.Parse()
+
+	groupName := os.Getenv("GROUP_NAME")
+	if groupName == "" || *certFile == "" || *keyFile == "" || *clientCAFile == "" {
+		log.Fatal("GROUP_NAME, -tls-cert, -tls-key and -client-ca are required")
+	}
+	s := &solver{
+		provider:  newProvider(),
+		groupName: groupName,
+		timeout:   *timeout,
+	}
+	defer s.provider.Close()
+	if err := s.provider.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	pem, err := os.ReadFile(*clientCAFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(pem) {
+		log.Fatalf("no certificates in %s", *clientCAFile)
+	}
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig: &tls.Config{
+			MinVersion: tls.VersionTLS12,
+			ClientCAs:  clientCAs,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		},
+	}
+	if err := server.ListenAndServeTLS(*certFile, *keyFile); !errors.Is(err, http.ErrServerClosed) {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+const (
+	// solverName is the name Issuers refer to the solver by.
+	solverName = "linode"
+	// webhookAPIVersion is the API version of the challenge payloads
+	// cert-manager sends to webhook solvers.
+	webhookAPIVersion = "webhook.acme.cert-manager.io/v1alpha1"
+)
+
+// challengePayload is the body of the requests cert-manager sends to webhook
+// solvers, and of their responses.
+type challengePayload struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *challengeRequest  `json:"request,omitempty"`
+	Response   *challengeResponse `json:"response,omitempty"`
+}
+
+// challengeRequest asks to present or clean up a DNS-01 challenge.
+type challengeRequest struct {
+	UID          string `json:"uid"`
+	Action       string `json:"action"`
+	Type         string `json:"type"`
+	DNSName      string `json:"dnsName"`
+	Key          string `json:"key"`
+	ResolvedFQDN string `json:"resolvedFQDN"`
+	ResolvedZone string `json:"resolvedZone"`
+}
+
+// challengeResponse is the outcome of a challengeRequest.
+type challengeResponse struct {
+	UID     string  `json:"uid"`
+	Success bool    `json:"success"`
+	Status  *status `json:"status,omitempty"`
+}
+
+// status is a Kubernetes Status, reporting why a request failed.
+type status struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Status     string `json:"status"`
+	Message    string `json:"message"`
+	Reason     string `json:"reason,omitempty"`
+	Code       int    `json:"code"`
+}
+
+// challengePrefix is the first label of the names of DNS-01 challenges.
+const challengePrefix = "_acme-challenge"
+
+// newProvider returns a provider that can only write the TXT records of
+// challenges, so that the solver can't be used to change other records of the
+// zones the token has access to.
+func newProvider() *linode.Provider {
+	return &linode.Provider{
+		FindParentZone:   true,
+		IdempotentAppend: true,
+		IdempotentDelete: true,
+		SkipExisting:     true,
+		AllowedNames:     []string{challengePrefix + "*"},
+		AllowedTypes:     []string{"TXT"},
+	}
+}
+
+// solver serves the webhook API group, presenting and cleaning up the
+// challenges cert-manager sends to it with the provider.
+type solver struct {
+	provider  *linode.Provider
+	groupName string
+	timeout   time.Duration
+}
+
+func (s *solver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	prefix := "/apis/" + s.groupName + "/v1alpha1"
+	switch {
+	case r.URL.Path == "/healthz":
+		w.Write([]byte("ok"))
+	case r.URL.Path == prefix && r.Method == http.MethodGet:
+		s.serveResources(w)
+	case r.URL.Path == prefix+"/"+solverName && r.Method == http.MethodPost:
+		s.serveChallenge(w, r)
+	default:
+		writeStatus(w, http.StatusNotFound, "NotFound", "the server could not find the requested resource")
+	}
+}
+
+// serveResources serves the discovery document of the API group, which the
+// API server checks to consider the webhook available.
+func (s *solver) serveResources(w http.ResponseWriter) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"apiVersion":   "v1",
+		"kind":         "APIResourceList",
+		"groupVersion": s.groupName + "/v1alpha1",
+		"resources": []map[string]any{{
+			"name":         solverName,
+			"singularName": solverName,
+			"namespaced":   false,
+			"kind":         "ChallengePayload",
+			"verbs":        []string{"create"},
+		}},
+	})
+}
+
+// serveChallenge presents or cleans up the challenge of the request.
+func (s *solver) serveChallenge(w http.ResponseWriter, r *http.Request) {
+	var payload challengePayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.Request == nil {
+		writeStatus(w, http.StatusBadRequest, "BadRequest", "the request is not a ChallengePayload")
+		return
+	}
+	req := payload.Request
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	resp := &challengeResponse{UID: req.UID, Success: true}
+	if err := s.solve(ctx, req); err != nil {
+		log.Printf("could not %s challenge for %s: %v", strings.ToLower(req.Action), req.DNSName, err)
+		resp.Success = false
+		resp.Status = &status{
+			APIVersion: "v1",
+			Kind:       "Status",
+			Status:     "Failure",
+			Message:    err.Error(),
+			Code:       http.StatusInternalServerError,
+		}
+	}
+	writeJSON(w, http.StatusOK, challengePayload{
+		APIVersion: webhookAPIVersion,
+		Kind:       "ChallengePayload",
+		Response:   resp,
+	})
+}
+
+// solve creates the TXT record of a challenge to present it, and deletes it
+// to clean it up. Other challenges of the same name, such as for a wildcard
+// and its apex, keep their records. cert-manager retries both actions, so
+// they have to be idempotent, which the provider's options take care of.
+func (s *solver) solve(ctx context.Context, req *challengeRequest) error {
+	if req.ResolvedFQDN == "" || req.ResolvedZone == "" || req.Key == "" {
+		return fmt.Errorf("incomplete challenge request")
+	}
+	if !strings.HasPrefix(strings.ToLower(req.ResolvedFQDN), challengePrefix+".") {
+		return fmt.Errorf("%s is not the name of a DNS-01 challenge", req.ResolvedFQDN)
+	}
+	record := []libdns.Record{libdns.TXT{
+		Name: libdns.RelativeName(req.ResolvedFQDN, req.ResolvedZone),
+		Text: req.Key,
+	}}
+	switch req.Action {
+	case "Present":
+		_, err := s.provider.AppendRecords(ctx, req.ResolvedZone, record)
+		return err
+	case "CleanUp":
+		_, err := s.provider.DeleteRecords(ctx, req.ResolvedZone, record)
+		return err
+	}
+	return fmt.Errorf("unsupported action %q", req.Action)
+}
+
+// writeStatus writes a Kubernetes Status reporting a failed request.
+func writeStatus(w http.ResponseWriter, code int, reason, message string) {
+	writeJSON(w, code, status{
+		APIVersion: "v1",
+		Kind:       "Status",
+		Status:     "Failure",
+		Message:    message,
+		Reason:     reason,
+		Code:       code,
+	})
+}
+
+// writeJSON writes v as the JSON body of a response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("could not write response: %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+)
+
+// newTestSolver returns a solver backed by a Fake with the domain example.com.
+func newTestSolver(t *testing.T) *solver {
+	t.Helper()
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := newProvider()
+	provider.API = fake
+	return &solver{
+		provider:  provider,
+		groupName: "acme.example.com",
+		timeout:   time.Minute,
+	}
+}
+
+// challenge sends a challenge request for the key to the solver and returns
+// its response.
+func challenge(t *testing.T, s *solver, action, key string) *challengeResponse {
+	t.Helper()
+	return challengeAt(t, s, action, "_acme-challenge.example.com.", key)
+}
+
+// challengeAt sends a challenge request for the key at the name to the solver
+// and returns its response.
+func challengeAt(t *testing.T, s *solver, action, fqdn, key string) *challengeResponse {
+	t.Helper()
+	body, err := json.Marshal(challengePayload{
+		APIVersion: webhookAPIVersion,
+		Kind:       "ChallengePayload",
+		Request: &challengeRequest{
+			UID:          "uid-" + key,
+			Action:       action,
+			Type:         "dns-01",
+			DNSName:      "example.com",
+			Key:          key,
+			ResolvedFQDN: fqdn,
+			ResolvedZone: "example.com.",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/acme.example.com/v1alpha1/linode", strings.NewReader(string(body))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("%s: status %d: %s", action, w.Code, w.Body)
+	}
+	var payload challengePayload
+	if err := json.Unmarshal(w.Body.Bytes(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Response == nil || payload.Response.UID != "uid-"+key {
+		t.Fatalf("%s: response %s, want one for uid-%s", action, w.Body, key)
+	}
+	return payload.Response
+}
+
+// challengeKeys returns the keys of the challenge records of the zone.
+func challengeKeys(t *testing.T, s *solver) []string {
+	t.Helper()
+	records, err := s.provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, record := range records {
+		if rr := record.RR(); rr.Name == "_acme-challenge" && rr.Type == "TXT" {
+			keys = append(keys, rr.Data)
+		}
+	}
+	return keys
+}
+
+func TestPresentAndCleanUp(t *testing.T) {
+	s := newTestSolver(t)
+	for _, key := range []string{"wildcard", "apex", "apex"} {
+		if resp := challenge(t, s, "Present", key); !resp.Success {
+			t.Fatalf("Present %s: %+v", key, resp.Status)
+		}
+	}
+	if keys := challengeKeys(t, s); len(keys) != 2 {
+		t.Fatalf("challenge records = %q, want wildcard and apex once", keys)
+	}
+	for _, key := range []string{"apex", "apex"} {
+		if resp := challenge(t, s, "CleanUp", key); !resp.Success {
+			t.Fatalf("CleanUp %s: %+v", key, resp.Status)
+		}
+	}
+	if keys := challengeKeys(t, s); len(keys) != 1 || keys[0] != "wildcard" {
+		t.Errorf("challenge records = %q, want wildcard", keys)
+	}
+}
+
+func TestPresentFailure(t *testing.T) {
+	s := newTestSolver(t)
+	s.provider.ReadOnly = true
+	resp := challenge(t, s, "Present", "key")
+	if resp.Success || resp.Status == nil || !strings.Contains(resp.Status.Message, linode.ErrReadOnly.Error()) {
+		t.Errorf("Present = %+v, want a failure for the read-only provider", resp)
+	}
+}
+
+func TestRejectsOtherNames(t *testing.T) {
+	s := newTestSolver(t)
+	for _, fqdn := range []string{"example.com.", "_dmarc.example.com.", "_acme-challenger.example.com."} {
+		if resp := challengeAt(t, s, "Present", fqdn, "v=spf1 -all"); resp.Success {
+			t.Errorf("Present at %s succeeded, want it rejected", fqdn)
+		}
+	}
+	records, err := s.provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Errorf("records = %v, want none", records)
+	}
+	// The provider refuses other names even if the request check is bypassed.
+	_, err = s.provider.AppendRecords(context.Background(), "example.com.", []libdns.Record{libdns.TXT{Name: "_dmarc", Text: "v=DMARC1"}})
+	if !errors.Is(err, linode.ErrOutOfScope) {
+		t.Errorf("AppendRecords(_dmarc) = %v, want ErrOutOfScope", err)
+	}
+}
+
+func TestDiscovery(t *testing.T) {
+	s := newTestSolver(t)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/apis/acme.example.com/v1alpha1", nil))
+	var list struct {
+		GroupVersion string `json:"groupVersion"`
+		Resources    []struct {
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatal(err)
+	}
+	if list.GroupVersion != "acme.example.com/v1alpha1" || len(list.Resources) != 1 || list.Resources[0].Name != solverName {
+		t.Errorf("discovery = %s, want the linode resource of acme.example.com/v1alpha1", w.Body)
+	}
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/apis/other.example.com/v1alpha1/linode", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other group: status %d, want 404", w.Code)
+	}
+}
@@ -0,0 +1,223 @@
+// Command linode-nsupdate is a DNS UPDATE (RFC 2136) gateway for Linode DNS.
+// It accepts TSIG-signed update messages, as sent by nsupdate, DHCP servers
+// and appliances, and applies them to the zone through the Linode API.
+//
+// The Linode API token is read from the LINODE_TOKEN environment variable and
+// the base64 TSIG secret from LINODE_TSIG_SECRET. Prerequisites (the answer
+// section of an update) are not supported.
+//
+// The updates of a message are first applied in order to the records read
+// from the zone, and the resulting creations and deletions are then written
+// together with linode.Provider.ApplyChanges. A malformed message, or one
+// whose records changed in the meantime, changes nothing, and a failed write
+// rolls back the writes to its RRset. The RRsets written before it keep their
+// changes, however, so a message is not applied atomically as RFC 2136
+// section 3.4 requires when Linode fails part way.
+//
+//	LINODE_TOKEN=... LINODE_TSIG_SECRET=... linode-nsupdate -zones example.com -tsig-key update-key
+//
+// To manage zones from the command line, see the libdns-linode command.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/miekg/dns"
+)
+
+func main() {
+	listen := flag.String("listen", ":53", "address to listen on, over UDP and TCP")
+	zones := flag.String("zones", "", "comma-separated zones that accept updates")
+	keyName := flag.String("tsig-key", "", "name of the TSIG key updates must be signed with")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout for applying one update message")
+	flag.Parse()
+
+	secret := os.Getenv("LINODE_TSIG_SECRET")
+	if *zones == "" || *keyName == "" || secret == "" {
+		log.Fatal("-zones, -tsig-key and LINODE_TSIG_SECRET are required")
+	}
+	g := &gateway{
+		provider: &linode.Provider{
+			IdempotentAppend: true,
+			IdempotentDelete: true,
+		},
+		zones:   make(map[string]bool),
+		timeout: *timeout,
+	}
+	for _, zone := range strings.Split(*zones, ",") {
+		g.zones[strings.ToLower(dns.Fqdn(strings.TrimSpace(zone)))] = true
+	}
+	defer g.provider.Close()
+
+	tsigSecret := map[string]string{dns.Fqdn(*keyName): secret}
+	errs := make(chan error, 2)
+	for _, network := range []string{"udp", "tcp"} {
+		server := &dns.Server{Addr: *listen, Net: network, Handler: g, TsigSecret: tsigSecret}
+		go func() { errs <- server.ListenAndServe() }()
+	}
+	log.Fatal(<-errs)
+}
+
+// gateway translates DNS UPDATE messages into provider record operations.
+type gateway struct {
+	provider *linode.Provider
+	zones    map[string]bool
+	timeout  time.Duration
+}
+
+func (g *gateway) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
+	reply := new(dns.Msg)
+	reply.SetRcode(r, g.update(w, r))
+	if tsig := r.IsTsig(); tsig != nil && w.TsigStatus() == nil {
+		reply.SetTsig(tsig.Hdr.Name, tsig.Algorithm, 300, time.Now().Unix())
+	}
+	if err := w.WriteMsg(reply); err != nil {
+		log.Printf("could not write reply to %s: %v", w.RemoteAddr(), err)
+	}
+}
+
+// update applies the update message and returns the response code.
+func (g *gateway) update(w dns.ResponseWriter, r *dns.Msg) int {
+	if r.Opcode != dns.OpcodeUpdate {
+		return dns.RcodeNotImplemented
+	}
+	if r.IsTsig() == nil || w.TsigStatus() != nil {
+		return dns.RcodeNotAuth
+	}
+	if len(r.Question) != 1 || r.Question[0].Qtype != dns.TypeSOA {
+		return dns.RcodeFormatError
+	}
+	zone := strings.ToLower(r.Question[0].Name)
+	if !g.zones[zone] {
+		return dns.RcodeNotAuth
+	}
+	if len(r.Answer) > 0 {
+		return dns.RcodeNotImplemented
+	}
+	class := r.Question[0].Qclass
+	for _, rr := range r.Ns {
+		header := rr.Header()
+		if !dns.IsSubDomain(zone, strings.ToLower(header.Name)) {
+			return dns.RcodeNotZone
+		}
+		if header.Class != class && header.Class != dns.ClassANY && header.Class != dns.ClassNONE {
+			return dns.RcodeFormatError
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+	changes, err := g.changes(ctx, zone, class, r.Ns)
+	if err == nil && len(changes.Create)+len(changes.Delete) > 0 {
+		_, err = g.provider.ApplyChanges(ctx, zone, changes)
+	}
+	if err != nil {
+		log.Printf("could not apply update from %s to %s: %v", w.RemoteAddr(), zone, err)
+		if errors.Is(err, linode.ErrReadOnly) || errors.Is(err, linode.ErrZoneFrozen) || errors.Is(err, linode.ErrOutOfScope) {
+			return dns.RcodeRefused
+		}
+		return dns.RcodeServerFailure
+	}
+	return dns.RcodeSuccess
+}
+
+// changes applies the updates of a message, as described in RFC 2136 section
+// 2.5, in order to the records of the zone, and returns the records to create
+// and delete to get the result.
+func (g *gateway) changes(ctx context.Context, zone string, class uint16, updates []dns.RR) (linode.Changes, error) {
+	live, err := g.provider.GetRecords(ctx, zone)
+	if err != nil {
+		return linode.Changes{}, err
+	}
+	records := append([]libdns.Record(nil), live...)
+	for _, rr := range updates {
+		header := rr.Header()
+		update := convertRR(zone, rr)
+		switch {
+		case header.Class == class:
+			if indexOf(records, update) < 0 {
+				records = append(records, update)
+			}
+		case header.Class == dns.ClassANY && header.Rrtype == dns.TypeANY:
+			records = remove(records, libdns.RR{Name: update.Name})
+		case header.Class == dns.ClassANY:
+			records = remove(records, libdns.RR{Name: update.Name, Type: update.Type})
+		default:
+			records = remove(records, update)
+		}
+	}
+	var changes linode.Changes
+	for _, record := range records {
+		if indexOf(live, record.RR()) < 0 {
+			changes.Create = append(changes.Create, record)
+		}
+	}
+	for _, record := range live {
+		if indexOf(records, record.RR()) < 0 {
+			changes.Delete = append(changes.Delete, record)
+		}
+	}
+	return changes, nil
+}
+
+// indexOf returns the index of the record with the name, type and data of rr,
+// or -1.
+func indexOf(records []libdns.Record, rr libdns.RR) int {
+	for i, record := range records {
+		if matches(record.RR(), rr) {
+			return i
+		}
+	}
+	return -1
+}
+
+// remove removes the records that match the name of filter, and its type and
+// data unless they are empty. Removing all records of the apex keeps its NS
+// records.
+func remove(records []libdns.Record, filter libdns.RR) []libdns.Record {
+	kept := records[:0:0]
+	for _, record := range records {
+		rr := record.RR()
+		if !matches(rr, filter) || (filter.Type == "" && filter.Name == "@" && rr.Type == "NS") {
+			kept = append(kept, record)
+		}
+	}
+	return kept
+}
+
+// matches reports whether rr has the name of filter, and its type and data
+// unless they are empty.
+func matches(rr, filter libdns.RR) bool {
+	switch {
+	case !strings.EqualFold(rr.Name, filter.Name):
+		return false
+	case filter.Type != "" && rr.Type != filter.Type:
+		return false
+	case filter.Data != "" && strings.TrimSuffix(rr.Data, ".") != strings.TrimSuffix(filter.Data, "."):
+		return false
+	}
+	return true
+}
+
+// convertRR converts a resource record of an update message.
+func convertRR(zone string, rr dns.RR) libdns.RR {
+	header := rr.Header()
+	data := strings.TrimSpace(strings.TrimPrefix(rr.String(), header.String()))
+	if txt, ok := rr.(*dns.TXT); ok {
+		data = strings.Join(txt.Txt, "")
+	}
+	return libdns.RR{
+		Name: libdns.RelativeName(header.Name, zone),
+		TTL:  time.Duration(header.Ttl) * time.Second,
+		Type: dns.TypeToString[header.Rrtype],
+		Data: data,
+	}
+}
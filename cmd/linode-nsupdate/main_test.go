@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/miekg/dns"
+)
+
+// newTestGateway returns a gateway for example.com backed by a Fake holding
+// the records.
+func newTestGateway(t *testing.T, records ...libdns.Record) *gateway {
+	t.Helper()
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: fake}
+	if _, err := provider.AppendRecords(context.Background(), "example.com.", records); err != nil {
+		t.Fatal(err)
+	}
+	return &gateway{provider: provider, zones: map[string]bool{"example.com.": true}, timeout: time.Minute}
+}
+
+// tsigWriter is a dns.ResponseWriter for signed messages.
+type tsigWriter struct {
+	dns.ResponseWriter
+}
+
+func (tsigWriter) TsigStatus() error    { return nil }
+func (tsigWriter) RemoteAddr() net.Addr { return &net.UDPAddr{} }
+
+// rr parses a resource record in the zone file format.
+func rr(t *testing.T, s string) dns.RR {
+	t.Helper()
+	rr, err := dns.NewRR(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rr
+}
+
+// send sends an update message with the updates added by build and returns
+// the response code.
+func send(t *testing.T, g *gateway, build func(msg *dns.Msg)) int {
+	t.Helper()
+	msg := new(dns.Msg)
+	msg.SetUpdate("example.com.")
+	build(msg)
+	msg.SetTsig("update-key.", dns.HmacSHA256, 300, time.Now().Unix())
+	return g.update(tsigWriter{}, msg)
+}
+
+// zoneRecords returns the records of the zone as sorted "name type data".
+func zoneRecords(t *testing.T, g *gateway) []string {
+	t.Helper()
+	records, err := g.provider.GetRecords(context.Background(), "example.com.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, record := range records {
+		rr := record.RR()
+		lines = append(lines, rr.Name+" "+rr.Type+" "+rr.Data)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func TestUpdateAppliesInOrder(t *testing.T) {
+	g := newTestGateway(t,
+		libdns.TXT{Name: "www", TTL: time.Hour, Text: "old"},
+		libdns.TXT{Name: "gone", TTL: time.Hour, Text: "gone"},
+	)
+	rcode := send(t, g, func(msg *dns.Msg) {
+		msg.RemoveRRset([]dns.RR{rr(t, "www.example.com. TXT old")})
+		msg.Insert([]dns.RR{rr(t, "www.example.com. 3600 IN TXT new")})
+		msg.Remove([]dns.RR{rr(t, "gone.example.com. TXT gone")})
+		msg.Insert([]dns.RR{rr(t, "added.example.com. 3600 IN TXT added")})
+		msg.Remove([]dns.RR{rr(t, "added.example.com. TXT added")})
+	})
+	if rcode != dns.RcodeSuccess {
+		t.Fatalf("rcode = %s, want NOERROR", dns.RcodeToString[rcode])
+	}
+	if got, want := strings.Join(zoneRecords(t, g), ", "), "www TXT new"; got != want {
+		t.Errorf("records = %s, want %s", got, want)
+	}
+}
+
+func TestUpdateRejectsMessageWithoutWriting(t *testing.T) {
+	g := newTestGateway(t, libdns.TXT{Name: "www", TTL: time.Hour, Text: "old"})
+	rcode := send(t, g, func(msg *dns.Msg) {
+		msg.RemoveRRset([]dns.RR{rr(t, "www.example.com. TXT old")})
+		msg.Insert([]dns.RR{rr(t, "www.example.com. 3600 IN TXT new")})
+		msg.Ns[len(msg.Ns)-1].Header().Class = dns.ClassCHAOS
+	})
+	if rcode != dns.RcodeFormatError {
+		t.Errorf("rcode = %s, want FORMERR", dns.RcodeToString[rcode])
+	}
+	if got, want := strings.Join(zoneRecords(t, g), ", "), "www TXT old"; got != want {
+		t.Errorf("records = %s, want %s unchanged", got, want)
+	}
+}
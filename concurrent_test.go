@@ -0,0 +1,143 @@
+package linode_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// TestConcurrentUse shares one provider between goroutines that get, append,
+// set and delete records while the record cache expires and refreshes in the
+// background, and the batch calls share the worker pool. Run it with -race.
+func TestConcurrentUse(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{
+		API:                  fake,
+		Concurrency:          4,
+		RecordCacheTTL:       time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+	}
+	defer provider.Close()
+
+	const goroutines, rounds = 8, 10
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		g := g
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name := fmt.Sprintf("host%d", g)
+			for i := 0; i < rounds; i++ {
+				records := []libdns.Record{
+					libdns.TXT{Name: name, Text: fmt.Sprintf("round %d a", i)},
+					libdns.TXT{Name: name, Text: fmt.Sprintf("round %d b", i)},
+				}
+				if _, err := provider.AppendRecords(ctx, testZone, records[:1]); err != nil {
+					t.Errorf("AppendRecords: %v", err)
+					return
+				}
+				if _, err := provider.SetRecords(ctx, testZone, records); err != nil {
+					t.Errorf("SetRecords: %v", err)
+					return
+				}
+				if _, err := provider.GetRecords(ctx, testZone); err != nil {
+					t.Errorf("GetRecords: %v", err)
+					return
+				}
+				if i < rounds-1 {
+					if _, err := provider.DeleteRecords(ctx, testZone, records); err != nil {
+						t.Errorf("DeleteRecords: %v", err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if t.Failed() {
+		return
+	}
+
+	records := mustGet(t, provider)
+	for g := 0; g < goroutines; g++ {
+		name := fmt.Sprintf("host%d", g)
+		want := []string{fmt.Sprintf("round %d a", rounds-1), fmt.Sprintf("round %d b", rounds-1)}
+		if got := rrsetData(records, name, "TXT"); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("TXT records of %s = %q, want %q", name, got, want)
+		}
+	}
+}
+
+// inFlightAPI counts the record creations in flight, in total and per
+// domain, and holds every creation for a while so that they can overlap.
+type inFlightAPI struct {
+	linode.DomainAPI
+	mutex                 sync.Mutex
+	inFlight, maxInFlight int
+	perDomain, maxPerZone map[int]int
+}
+
+func (a *inFlightAPI) CreateDomainRecord(ctx context.Context, domainID int, opts linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error) {
+	a.mutex.Lock()
+	a.inFlight++
+	a.perDomain[domainID]++
+	if a.inFlight > a.maxInFlight {
+		a.maxInFlight = a.inFlight
+	}
+	if a.perDomain[domainID] > a.maxPerZone[domainID] {
+		a.maxPerZone[domainID] = a.perDomain[domainID]
+	}
+	a.mutex.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	a.mutex.Lock()
+	a.inFlight--
+	a.perDomain[domainID]--
+	a.mutex.Unlock()
+	return a.DomainAPI.CreateDomainRecord(ctx, domainID, opts)
+}
+
+// TestWorkerPoolSharedAcrossZones checks that batch calls on different zones
+// write on the shared worker pool at the same time, never above Concurrency
+// together, while calls on the same zone don't interleave.
+func TestWorkerPoolSharedAcrossZones(t *testing.T) {
+	fake := new(linodetest.Fake)
+	zones := []string{"a.example.", "b.example.", "c.example."}
+	for _, zone := range zones {
+		fake.AddDomain(strings.TrimSuffix(zone, "."))
+	}
+	api := &inFlightAPI{DomainAPI: fake, perDomain: map[int]int{}, maxPerZone: map[int]int{}}
+	provider := &linode.Provider{API: api, Concurrency: 2}
+	defer provider.Close()
+
+	var wg sync.WaitGroup
+	for i, zone := range append(zones, zones...) {
+		i, zone := i, zone
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			record := libdns.TXT{Name: fmt.Sprintf("host%d", i), Text: "x"}
+			if _, err := provider.AppendRecords(context.Background(), zone, []libdns.Record{record}); err != nil {
+				t.Errorf("AppendRecords(%s): %v", zone, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if api.maxInFlight != 2 {
+		t.Errorf("at most %d creations were in flight, want Concurrency (2) across zones", api.maxInFlight)
+	}
+	for domainID, max := range api.maxPerZone {
+		if max > 1 {
+			t.Errorf("%d creations of single-record calls were in flight in domain %d, want calls on a zone serialized", max, domainID)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package linode
+
+import (
+	"os"
+	"strings"
+)
+
+// expandPlaceholders replaces the {env.VAR} placeholders in s with the values
+// of the environment variables, so that configuration files can reference
+// secrets instead of containing them. Unset variables expand to "".
+func expandPlaceholders(s string) string {
+	const prefix = "{env."
+	var b strings.Builder
+	for {
+		start := strings.Index(s, prefix)
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(s[start:], '}')
+		if end < 0 {
+			break
+		}
+		b.WriteString(s[:start])
+		b.WriteString(os.Getenv(s[start+len(prefix) : start+end]))
+		s = s[start+end+1:]
+	}
+	if b.Len() == 0 {
+		return s
+	}
+	b.WriteString(s)
+	return b.String()
+}
+
+// configValue returns the setting with its placeholders expanded, or the
+// value of the environment variable if the setting is empty.
+func configValue(setting, envVar string) string {
+	if setting == "" {
+		return os.Getenv(envVar)
+	}
+	return expandPlaceholders(setting)
+}
+
+// apiToken returns APIToken, or LINODE_TOKEN if it is empty.
+func (p *Provider) apiToken() string {
+	return configValue(p.APIToken, "LINODE_TOKEN")
+}
+
+// apiURL returns APIURL, or LINODE_API_URL if it is empty.
+func (p *Provider) apiURL() string {
+	return configValue(p.APIURL, "LINODE_API_URL")
+}
+
+// apiVersion returns APIVersion, or LINODE_API_VERSION if it is empty.
+func (p *Provider) apiVersion() string {
+	return configValue(p.APIVersion, "LINODE_API_VERSION")
+}
@@ -0,0 +1,129 @@
+package linode
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// csvHeader is the header row of exported CSV files.
+var csvHeader = []string{"name", "type", "ttl", "data"}
+
+// ExportCSV writes the records of the zone as CSV with the columns name, type,
+// ttl (in seconds) and data, preceded by a header row.
+func (p *Provider) ExportCSV(ctx context.Context, zone string, w io.Writer) error {
+	records, err := p.GetRecords(ctx, zone)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, record := range records {
+		rr := record.RR()
+		if err := cw.Write([]string{rr.Name, rr.Type, strconv.Itoa(int(rr.TTL.Seconds())), rr.Data}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CSVImportOptions configures ImportCSV.
+type CSVImportOptions struct {
+	// DryRun parses and validates the rows and reports the outcomes adding
+	// them would have, as WithDryRun does, without changing the zone.
+	DryRun bool
+}
+
+// CSVRowResult is the outcome of importing a single CSV row.
+type CSVRowResult struct {
+	// Line is the line number of the row in the CSV input.
+	Line int
+	RecordResult
+}
+
+// ImportCSV adds the records read from CSV in the format written by ExportCSV
+// to the zone. The header row is optional. If any row is invalid, no records
+// are added and the invalid rows are reported as failed.
+func (p *Provider) ImportCSV(ctx context.Context, zone string, r io.Reader, opts CSVImportOptions) ([]CSVRowResult, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	var results []CSVRowResult
+	var records []libdns.Record
+	invalid := 0
+	for {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return results, err
+			}
+			invalid++
+			results = append(results, CSVRowResult{Line: parseErr.Line, RecordResult: RecordResult{Outcome: OutcomeFailed, Err: err}})
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		if len(results) == 0 && len(records) == 0 && strings.EqualFold(row[0], csvHeader[0]) && strings.EqualFold(row[1], csvHeader[1]) {
+			continue
+		}
+		record, err := parseCSVRow(zone, row)
+		if err != nil {
+			invalid++
+			results = append(results, CSVRowResult{Line: line, RecordResult: RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}})
+			continue
+		}
+		records = append(records, record)
+		results = append(results, CSVRowResult{Line: line, RecordResult: RecordResult{Record: record, Outcome: OutcomeSkipped}})
+	}
+	if invalid > 0 {
+		return results, fmt.Errorf("%d of the CSV rows are invalid", invalid)
+	}
+	if len(records) == 0 {
+		return results, nil
+	}
+	if opts.DryRun {
+		ctx = WithDryRun(ctx)
+	}
+	recordResults, err := p.AppendRecordsWithResults(ctx, zone, records)
+	if recordResults != nil {
+		for i := range results {
+			results[i].RecordResult = recordResults[i]
+		}
+	}
+	return results, err
+}
+
+func parseCSVRow(zone string, row []string) (libdns.Record, error) {
+	rr := libdns.RR{
+		Name: strings.TrimSpace(row[0]),
+		Type: strings.ToUpper(strings.TrimSpace(row[1])),
+		Data: row[3],
+	}
+	if ttl := strings.TrimSpace(row[2]); ttl != "" {
+		seconds, err := strconv.Atoi(ttl)
+		if err != nil || seconds < 0 {
+			return rr, fmt.Errorf("%w: invalid TTL %q", ErrInvalidRecord, ttl)
+		}
+		rr.TTL = time.Duration(seconds) * time.Second
+	}
+	record, err := rr.Parse()
+	if err != nil {
+		return rr, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	if err := validateRecord(zone, record); err != nil {
+		return record, err
+	}
+	return record, nil
+}
@@ -0,0 +1,50 @@
+package linode_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/libdns/linode"
+)
+
+func TestImportCSVParseErrorOnFirstRow(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	input := "w\"ww,A,300,1.2.3.4\nmail,A,300,5.6.7.8\n"
+	results, err := provider.ImportCSV(context.Background(), testZone, strings.NewReader(input), linode.CSVImportOptions{})
+	if err == nil {
+		t.Fatal("expected an error for the invalid row")
+	}
+	if len(results) == 0 || results[0].Line != 1 || results[0].Outcome != linode.OutcomeFailed {
+		t.Fatalf("results = %+v, want line 1 failed", results)
+	}
+	if records := fake.Records(domainID); len(records) != 0 {
+		t.Fatalf("zone has %d records, want none", len(records))
+	}
+}
+
+func TestImportCSVDryRun(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	mustAppend(t, provider, mustParse(t, "mail", "A", "5.6.7.8"))
+	provider.SkipExisting = true
+	input := "name,type,ttl,data\nwww,A,300,1.2.3.4\nmail,A,0,5.6.7.8\n"
+	results, err := provider.ImportCSV(context.Background(), testZone, strings.NewReader(input), linode.CSVImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ImportCSV: %v", err)
+	}
+	want := []struct {
+		line    int
+		outcome linode.Outcome
+	}{{2, linode.OutcomeCreated}, {3, linode.OutcomeNoOp}}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Line != w.line || results[i].Outcome != w.outcome {
+			t.Errorf("result %d = line %d %s, want line %d %s", i, results[i].Line, results[i].Outcome, w.line, w.outcome)
+		}
+	}
+	if records := fake.Records(domainID); len(records) != 1 {
+		t.Fatalf("zone has %d records, want 1", len(records))
+	}
+}
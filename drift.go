@@ -0,0 +1,257 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// DesiredStateFunc returns the records that a zone is expected to contain.
+type DesiredStateFunc func(ctx context.Context, zone string) ([]libdns.Record, error)
+
+// DesiredStateFromSnapshot returns a DesiredStateFunc that reads the desired
+// records from a JSON file holding an object that maps zone names to lists of
+// records in the libdns.RR format.
+func DesiredStateFromSnapshot(path string) DesiredStateFunc {
+	return func(ctx context.Context, zone string) ([]libdns.Record, error) {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var snapshot map[string][]libdns.RR
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("could not parse snapshot %s: %v", path, err)
+		}
+		rrs, ok := snapshot[zone]
+		if !ok {
+			rrs, ok = snapshot[zoneKey(zone)]
+		}
+		if !ok {
+			return nil, fmt.Errorf("snapshot %s has no records for zone %s", path, zone)
+		}
+		records := make([]libdns.Record, 0, len(rrs))
+		for _, rr := range rrs {
+			record, err := rr.Parse()
+			if err != nil {
+				return nil, fmt.Errorf("could not parse record %s %s in snapshot: %v", rr.Name, rr.Type, err)
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	}
+}
+
+// DesiredStateFromZoneFile returns a DesiredStateFunc that reads the desired
+// records from a zone file in the standard (BIND) format, see ParseZoneFile.
+// The path may contain "{zone}", which is replaced by the name of the zone
+// without the trailing dot, such as "/etc/bind/{zone}.db", to read a file per
+// zone.
+func DesiredStateFromZoneFile(path string) DesiredStateFunc {
+	return func(ctx context.Context, zone string) ([]libdns.Record, error) {
+		zonePath := strings.ReplaceAll(path, "{zone}", strings.TrimSuffix(zone, "."))
+		f, err := os.Open(zonePath)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		records, err := ParseZoneFile(f, zone)
+		if err != nil {
+			return nil, fmt.Errorf("could not read zone file %s: %v", zonePath, err)
+		}
+		return records, nil
+	}
+}
+
+// Drift describes how the live records of a zone differ from its desired state.
+type Drift struct {
+	Zone string
+	// Missing are desired records that are not in the zone.
+	Missing []libdns.Record
+	// Unexpected are records in the zone that are not desired.
+	Unexpected []libdns.Record
+	// Time is when the zone was checked.
+	Time time.Time
+}
+
+// HasDrift reports whether the zone differs from its desired state.
+func (d Drift) HasDrift() bool {
+	return len(d.Missing) > 0 || len(d.Unexpected) > 0
+}
+
+// DriftMonitor periodically compares zones against their desired state, so that
+// manual edits made outside of the provider are noticed.
+type DriftMonitor struct {
+	// Provider is used to read the live records.
+	Provider *Provider
+	// Zones are the zones to check.
+	Zones []string
+	// Desired returns the desired records of a zone, see
+	// DesiredStateFromSnapshot and DesiredStateFromZoneFile.
+	Desired DesiredStateFunc
+	// Interval is the time between checks, one minute by default.
+	Interval time.Duration
+	// OnDrift is called for every check that found drift. Drift is also
+	// reported to the provider's webhook, if one is configured.
+	OnDrift func(Drift)
+	// OnError is called when a zone could not be checked or repaired.
+	OnError func(zone string, err error)
+	// Enforce makes the monitor reapply the desired state when it finds
+	// drift, see Repair: missing records are created or updated in place and
+	// unexpected records are deleted, as long as they are managed.
+	Enforce bool
+	// Managed reports whether an unexpected record may be deleted when
+	// enforcing. By default, only records whose name and type appear in the
+	// desired state are considered managed, so that records created by other
+	// means are never removed.
+	Managed func(zone string, record libdns.Record) bool
+	// OnRepair is called after drift was repaired.
+	OnRepair func(Drift)
+}
+
+// Run checks the zones every interval until the context is canceled.
+func (m *DriftMonitor) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		m.checkAll(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *DriftMonitor) checkAll(ctx context.Context) {
+	for _, zone := range m.Zones {
+		drift, err := m.Check(ctx, zone)
+		if err != nil {
+			if m.OnError != nil {
+				m.OnError(zone, err)
+			}
+			continue
+		}
+		if !drift.HasDrift() {
+			continue
+		}
+		if m.OnDrift != nil {
+			m.OnDrift(drift)
+		}
+		m.Provider.notifyDrift(drift)
+		if m.Enforce {
+			if err := m.Repair(ctx, drift); err != nil {
+				if m.OnError != nil {
+					m.OnError(zone, err)
+				}
+				continue
+			}
+			if m.OnRepair != nil {
+				m.OnRepair(drift)
+			}
+		}
+	}
+}
+
+// Repair reapplies the desired state of a zone. The RRsets with missing or
+// managed unexpected records are replaced with their desired records like
+// SetRecords does, so that records differing only in TTL are updated in place
+// and single-valued RRsets such as CNAME are changed without a conflict.
+// Unexpected records that are not managed are kept, and managed ones outside
+// the desired RRsets are deleted.
+func (m *DriftMonitor) Repair(ctx context.Context, drift Drift) error {
+	desiredRecords, err := m.Desired(ctx, drift.Zone)
+	if err != nil {
+		return fmt.Errorf("could not get desired state of zone %s: %w", drift.Zone, err)
+	}
+	desired := make(map[rrsetKey]bool, len(desiredRecords))
+	for _, record := range desiredRecords {
+		desired[newRRSetKey(drift.Zone, record)] = true
+	}
+	managed := m.Managed
+	if managed == nil {
+		managed = func(zone string, record libdns.Record) bool {
+			return desired[newRRSetKey(zone, record)]
+		}
+	}
+	drifted := make(map[rrsetKey]bool)
+	for _, record := range drift.Missing {
+		drifted[newRRSetKey(drift.Zone, record)] = true
+	}
+	var kept, deletions []libdns.Record
+	for _, record := range drift.Unexpected {
+		key := newRRSetKey(drift.Zone, record)
+		switch {
+		case !managed(drift.Zone, record):
+			kept = append(kept, record)
+		case desired[key]:
+			drifted[key] = true
+		default:
+			deletions = append(deletions, record)
+		}
+	}
+	// Unmanaged records of the replaced RRsets are passed first, so that
+	// they keep their data and TTL.
+	var records []libdns.Record
+	for _, record := range kept {
+		if drifted[newRRSetKey(drift.Zone, record)] {
+			records = append(records, record)
+		}
+	}
+	for _, record := range desiredRecords {
+		if drifted[newRRSetKey(drift.Zone, record)] {
+			records = append(records, record)
+		}
+	}
+	if len(records) > 0 {
+		if _, err := m.Provider.SetRecords(ctx, drift.Zone, records); err != nil {
+			return fmt.Errorf("could not reapply drifted records: %w", err)
+		}
+	}
+	if len(deletions) > 0 {
+		if _, err := m.Provider.DeleteRecords(ctx, drift.Zone, deletions); err != nil {
+			return fmt.Errorf("could not delete unexpected records: %w", err)
+		}
+	}
+	return nil
+}
+
+// Check compares the live records of the zone against its desired state once.
+func (m *DriftMonitor) Check(ctx context.Context, zone string) (Drift, error) {
+	desired, err := m.Desired(ctx, zone)
+	if err != nil {
+		return Drift{}, fmt.Errorf("could not get desired state of zone %s: %w", zone, err)
+	}
+	live, err := m.Provider.liveRecords(ctx, zone)
+	if err != nil {
+		return Drift{}, err
+	}
+	missing, unexpected := m.Provider.diffRecords(zone, live, desired)
+	return Drift{
+		Zone:       zone,
+		Missing:    missing,
+		Unexpected: unexpected,
+		Time:       time.Now(),
+	}, nil
+}
+
+// liveRecords fetches the records of the zone from Linode, bypassing the cache.
+func (p *Provider) liveRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	return p.fetchRecords(withZone(ctx, zone), zone)
+}
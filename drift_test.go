@@ -0,0 +1,133 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+func TestDriftFromZoneFile(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	mustAppend(t, provider,
+		libdns.Address{Name: "www", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.1")},
+		libdns.Address{Name: "old", TTL: time.Hour, IP: netip.MustParseAddr("192.0.2.9")},
+	)
+	dir := t.TempDir()
+	zoneFile := `$TTL 3600
+@	IN	SOA	ns1.linode.com. admin.example.com. 1 14400 14400 1209600 86400
+@	IN	NS	ns1.linode.com.
+www	IN	A	192.0.2.1
+mail	IN	A	192.0.2.2
+`
+	if err := os.WriteFile(filepath.Join(dir, "example.com.db"), []byte(zoneFile), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	monitor := &linode.DriftMonitor{
+		Provider: provider,
+		Desired:  linode.DesiredStateFromZoneFile(filepath.Join(dir, "{zone}.db")),
+	}
+	drift, err := monitor.Check(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(drift.Missing) != 1 || drift.Missing[0].RR().Name != "mail" {
+		t.Errorf("Missing = %v, want mail", drift.Missing)
+	}
+	if len(drift.Unexpected) != 1 || drift.Unexpected[0].RR().Name != "old" {
+		t.Errorf("Unexpected = %v, want old", drift.Unexpected)
+	}
+}
+
+func TestDriftNormalizesTTLs(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	provider.DefaultTTL = 2 * time.Hour
+	mustAppend(t, provider,
+		libdns.TXT{Name: "rounded", TTL: time.Hour, Text: "a"},
+		libdns.TXT{Name: "default", Text: "b"},
+	)
+	monitor := &linode.DriftMonitor{
+		Provider: provider,
+		Desired: func(context.Context, string) ([]libdns.Record, error) {
+			return []libdns.Record{
+				// Linode stores 10 minutes as one hour.
+				libdns.TXT{Name: "rounded", TTL: 10 * time.Minute, Text: "a"},
+				libdns.TXT{Name: "default", Text: "b"},
+			}, nil
+		},
+	}
+	drift, err := monitor.Check(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if drift.HasDrift() {
+		t.Errorf("drift = %+v, want none", drift)
+	}
+}
+
+func TestRepairUpdatesInPlace(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	mustAppend(t, provider,
+		libdns.TXT{Name: "ttl", TTL: time.Hour, Text: "a"},
+		libdns.CNAME{Name: "alias", TTL: time.Hour, Target: "old.example.com."},
+		libdns.TXT{Name: "other", TTL: time.Hour, Text: "unmanaged"},
+	)
+	ids := func() map[string]int {
+		ids := map[string]int{}
+		for _, record := range fake.Records(domainID) {
+			ids[record.Name] = record.ID
+		}
+		return ids
+	}
+	before := ids()
+	desired := []libdns.Record{
+		libdns.TXT{Name: "ttl", TTL: 2 * time.Hour, Text: "a"},
+		libdns.CNAME{Name: "alias", TTL: time.Hour, Target: "new.example.com."},
+	}
+	monitor := &linode.DriftMonitor{
+		Provider: provider,
+		Desired: func(context.Context, string) ([]libdns.Record, error) {
+			return desired, nil
+		},
+	}
+	drift, err := monitor.Check(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(drift.Missing) != 2 || len(drift.Unexpected) != 3 {
+		t.Fatalf("drift = %+v, want 2 missing and 3 unexpected records", drift)
+	}
+	if err := monitor.Repair(context.Background(), drift); err != nil {
+		t.Fatalf("Repair: %v", err)
+	}
+	if after := ids(); len(after) != 3 || after["ttl"] != before["ttl"] || after["alias"] != before["alias"] || after["other"] != before["other"] {
+		t.Errorf("record IDs = %v, want the records of %v updated in place", after, before)
+	}
+	drift, err = monitor.Check(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if len(drift.Missing) != 0 || len(drift.Unexpected) != 1 || drift.Unexpected[0].RR().Name != "other" {
+		t.Errorf("drift after repair = %+v, want only the unmanaged record", drift)
+	}
+}
+
+func TestRepairWrapsDesiredError(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	errDesired := errors.New("desired state unavailable")
+	monitor := &linode.DriftMonitor{
+		Provider: provider,
+		Desired: func(context.Context, string) ([]libdns.Record, error) {
+			return nil, errDesired
+		},
+	}
+	if err := monitor.Repair(context.Background(), linode.Drift{Zone: testZone}); !errors.Is(err, errDesired) {
+		t.Errorf("Repair = %v, want it to wrap the Desired error", err)
+	}
+}
@@ -0,0 +1,217 @@
+package linode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+// ErrUnsupportedRecordType is returned when a record has a type that Linode
+// does not support.
+var ErrUnsupportedRecordType = errors.New("unsupported record type")
+
+// ErrUnsupportedType is the former name of ErrUnsupportedRecordType.
+//
+// Deprecated: Use ErrUnsupportedRecordType.
+var ErrUnsupportedType = ErrUnsupportedRecordType
+
+// ErrZoneNotFound is returned when no Linode domain matches a zone.
+var ErrZoneNotFound = errors.New("zone not found")
+
+// ErrRecordNotFound is returned when a record to update or delete does not
+// exist. It wraps the error of the Linode API, if any.
+var ErrRecordNotFound = errors.New("record not found")
+
+// supportedRecordTypes are the record types accepted by the Linode Domains API.
+var supportedRecordTypes = []string{"A", "AAAA", "NS", "MX", "CNAME", "TXT", "SRV", "PTR", "CAA"}
+
+func isSupportedRecordType(recordType string) bool {
+	for _, t := range supportedRecordTypes {
+		if strings.EqualFold(t, recordType) {
+			return true
+		}
+	}
+	return false
+}
+
+func unsupportedTypeError(recordType string) error {
+	return fmt.Errorf("%w: %s (supported: %s)", ErrUnsupportedRecordType, recordType, strings.Join(supportedRecordTypes, ", "))
+}
+
+// ErrReadOnly is returned when writing to a zone that is configured as read-only.
+var ErrReadOnly = errors.New("zone is read-only")
+
+// ErrZoneFrozen is returned when writing to a zone frozen with FreezeZone.
+var ErrZoneFrozen = errors.New("zone is frozen")
+
+// ErrOutOfScope is returned when writing a record whose name or type is not
+// allowed by Provider.AllowedNames, Provider.AllowedTypes or Provider.DeniedTypes.
+var ErrOutOfScope = errors.New("record name is out of scope")
+
+// ErrTooManyDeletions is returned when a call would delete more records than
+// allowed by Provider.MaxDeletionsPerCall or Provider.MaxDeletionPercent.
+var ErrTooManyDeletions = errors.New("too many records would be deleted")
+
+// ErrNotConfirmed is returned when Provider.Confirm rejected the destructive
+// changes of a call.
+var ErrNotConfirmed = errors.New("destructive changes were not confirmed")
+
+// ErrInvalidRecord is returned when a record violates one of Linode's limits.
+var ErrInvalidRecord = errors.New("invalid record")
+
+// ErrMalformedRecord is returned with Provider.StrictParsing for a record
+// stored at Linode whose data does not fit its type.
+var ErrMalformedRecord = errors.New("malformed record")
+
+// AmbiguousDomainError is returned when several Linode domains match a zone.
+type AmbiguousDomainError struct {
+	Zone       string
+	Candidates []DomainCandidate
+}
+
+// DomainCandidate is one of the domains matching a zone.
+type DomainCandidate struct {
+	ID     int
+	Status linodego.DomainStatus
+}
+
+func (e *AmbiguousDomainError) Error() string {
+	candidates := make([]string, 0, len(e.Candidates))
+	for _, c := range e.Candidates {
+		candidates = append(candidates, fmt.Sprintf("%d (%s)", c.ID, c.Status))
+	}
+	return fmt.Sprintf("%d domains match zone %s: %s", len(e.Candidates), e.Zone, strings.Join(candidates, ", "))
+}
+
+// RecordError attributes an error to a specific record of a batch.
+type RecordError struct {
+	// Index is the position of the record in the input slice.
+	Index int
+	// Record is the record that caused the error.
+	Record libdns.Record
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	rr := e.Record.RR()
+	return fmt.Sprintf("record %d (%s %s): %v", e.Index, rr.Name, rr.Type, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// BatchError aggregates the errors of a batch operation that continued past
+// failing records, see Provider.ContinueOnError. AppendRecords, SetRecords
+// and DeleteRecords also return it when they stop at a failing record, so
+// that callers can tell which records were written and retry only the rest.
+type BatchError struct {
+	Errors []*RecordError
+	// Results holds the result of every record of the batch, as returned by
+	// the *WithResults variants of the operations.
+	Results []RecordResult
+
+	mutex sync.Mutex
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d of the records failed: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (e *BatchError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	// Records processed concurrently fail in any order.
+	sort.Slice(e.Errors, func(i, j int) bool {
+		return e.Errors[i].Index < e.Errors[j].Index
+	})
+	return e
+}
+
+// newBatchError returns the error of a batch operation with the results of
+// its records attached. An error that no record failed with, such as a
+// canceled context, is returned as is.
+func newBatchError(results []RecordResult, err error) error {
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		batchErr.Results = results
+		return err
+	}
+	if err == nil || results == nil {
+		return err
+	}
+	batchErr = &BatchError{Results: results}
+	for i, result := range results {
+		if result.Outcome == OutcomeFailed {
+			batchErr.Errors = append(batchErr.Errors, &RecordError{Index: i, Record: result.Record, Err: result.Err})
+		}
+	}
+	if len(batchErr.Errors) == 0 {
+		return err
+	}
+	return batchErr
+}
+
+// errorStatus returns the HTTP status code of a Linode API error, or 0.
+func errorStatus(err error) int {
+	var apiErr *linodego.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	var apiErrValue linodego.Error
+	if errors.As(err, &apiErrValue) {
+		return apiErrValue.Code
+	}
+	return 0
+}
+
+// recordNotFoundError wraps a not found error of the Linode API for a record
+// with ErrRecordNotFound.
+func recordNotFoundError(err error) error {
+	if errorStatus(err) == http.StatusNotFound {
+		return fmt.Errorf("%w: %w", ErrRecordNotFound, err)
+	}
+	return err
+}
+
+// isConflictError reports whether the error means that a record was changed or
+// removed concurrently.
+func isConflictError(err error) bool {
+	status := errorStatus(err)
+	return status == http.StatusNotFound || status == http.StatusConflict
+}
+
+// isOutageError reports whether the error means that the Linode API could not
+// be reached or failed to handle the request.
+func isOutageError(err error) bool {
+	status := errorStatus(err)
+	return status == linodego.ErrorFromError || status >= http.StatusInternalServerError
+}
+
+// isDuplicateError reports whether the error may mean that a create was rejected
+// because the record already exists. Linode reports duplicates as a bad
+// request, so callers must confirm by looking the record up.
+func isDuplicateError(err error) bool {
+	status := errorStatus(err)
+	return status == http.StatusBadRequest || status == http.StatusConflict
+}
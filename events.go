@@ -0,0 +1,134 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// ZoneChange is a change to a domain or its records reported by Linode's
+// account events, whether it was made through the provider or not.
+type ZoneChange struct {
+	// EventID is the ID of the Linode event.
+	EventID int
+	// Zone is the domain that was changed.
+	Zone string
+	// Action is the Linode event action, such as "domain_record_create".
+	Action string
+	// Record is the name of the changed record, if the event names one.
+	Record string
+	// Username is the Linode user who made the change.
+	Username string
+	// Time is when the change was made.
+	Time time.Time
+}
+
+// EventWatcher polls Linode's account events for changes to domains and their
+// records, and drops the cached records of the changed zones. It is much
+// cheaper than comparing full record lists to detect changes.
+type EventWatcher struct {
+	// Provider is used to read the events and holds the caches.
+	Provider *Provider
+	// Interval is the time between polls, 30 seconds by default.
+	Interval time.Duration
+	// OnChange is called for every change, oldest first.
+	OnChange func(ZoneChange)
+	// OnError is called when the events could not be read.
+	OnError func(error)
+
+	lastID int
+	since  time.Time
+}
+
+// Run polls the events every interval until the context is canceled. Only
+// changes made after the first poll are reported.
+func (w *EventWatcher) Run(ctx context.Context) error {
+	interval := w.Interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		changes, err := w.Poll(ctx)
+		if err != nil && w.OnError != nil {
+			w.OnError(err)
+		}
+		if w.OnChange != nil {
+			for _, change := range changes {
+				w.OnChange(change)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll returns the changes since the previous poll, or since the first poll
+// was made.
+func (w *EventWatcher) Poll(ctx context.Context) ([]ZoneChange, error) {
+	if w.since.IsZero() {
+		w.since = time.Now().UTC()
+	}
+	p := w.Provider
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	filter := map[string]interface{}{
+		"entity.type": linodego.EntityDomain,
+		"+order_by":   "id",
+		"+order":      "asc",
+	}
+	if w.lastID > 0 {
+		filter["id"] = map[string]int{"+gt": w.lastID}
+	} else {
+		filter["created"] = map[string]string{"+gte": w.since.Format("2006-01-02T15:04:05")}
+	}
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return nil, err
+	}
+	events, err := p.api.ListEvents(ctx, linodego.NewListOptions(0, string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not list events: %w", err)
+	}
+	var changes []ZoneChange
+	for _, event := range events {
+		if event.ID > w.lastID {
+			w.lastID = event.ID
+		}
+		if event.Entity == nil || !strings.HasPrefix(string(event.Action), "domain") {
+			continue
+		}
+		change := ZoneChange{
+			EventID:  event.ID,
+			Zone:     event.Entity.Label,
+			Action:   string(event.Action),
+			Username: event.Username,
+		}
+		if event.SecondaryEntity != nil {
+			change.Record = event.SecondaryEntity.Label
+		}
+		if event.Created != nil {
+			change.Time = *event.Created
+		}
+		p.invalidateRecords(change.Zone)
+		if change.Action == "domain_create" || change.Action == "domain_delete" {
+			p.uncacheDomainID(change.Zone)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
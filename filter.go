@@ -0,0 +1,70 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+// GetRecordsFiltered returns the records of the zone with the given name and
+// type, such as the "_acme-challenge" TXT records, letting the Linode API
+// select them so that large zones are not listed in full. An empty name or
+// type matches any. Unlike GetRecords, it doesn't use the record cache.
+func (p *Provider) GetRecordsFiltered(ctx context.Context, zone, name, recordType string) ([]libdns.Record, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	parent, err := p.parentZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, parent)
+	domainID, err := p.getDomainIDByZone(ctx, parent)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", parent, err)
+	}
+	f := linodego.Filter{}
+	if name != "" {
+		name = normalizeName(parent, libdns.AbsoluteName(name, zone))
+		if name == "@" {
+			f.AddField(linodego.Eq, "name", "")
+		} else {
+			f.AddField(linodego.Eq, "name", name)
+		}
+	}
+	if recordType != "" {
+		f.AddField(linodego.Eq, "type", strings.ToUpper(recordType))
+	}
+	filter, err := f.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	records, err := p.listFilteredDomainRecords(ctx, parent, domainID, string(filter))
+	if err != nil {
+		return nil, err
+	}
+	// Match again in case the API, or a DomainAPI fake, ignores the filter.
+	var matches []libdns.Record
+	for _, record := range records {
+		rr := record.RR()
+		if name != "" && normalizeName(parent, rr.Name) != name {
+			continue
+		}
+		if recordType != "" && !strings.EqualFold(rr.Type, recordType) {
+			continue
+		}
+		if parent != zone {
+			record = rebaseRecord(record, parent, zone)
+		}
+		matches = append(matches, record)
+	}
+	return matches, nil
+}
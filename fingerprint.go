@@ -0,0 +1,41 @@
+package linode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+
+	"github.com/libdns/libdns"
+)
+
+// ZoneFingerprint returns a hash of the records of the zone, which changes
+// whenever a record's name, type, TTL or data changes, but not when the
+// records are merely listed in another order or get new IDs. Callers can
+// compare it with the fingerprint of their last sync to skip full diffs. It
+// lists the records from Linode, bypassing the record cache, so that changes
+// made outside this provider are noticed.
+func (p *Provider) ZoneFingerprint(ctx context.Context, zone string) (string, error) {
+	records, err := p.liveRecords(ctx, zone)
+	if err != nil {
+		return "", err
+	}
+	return fingerprintRecords(zone, records), nil
+}
+
+// fingerprintRecords hashes the records independently of their order.
+func fingerprintRecords(zone string, records []libdns.Record) string {
+	lines := make([]string, 0, len(records))
+	for _, record := range records {
+		key := newRecordKey(zone, record)
+		ttl := strconv.FormatInt(int64(record.RR().TTL.Seconds()), 10)
+		lines = append(lines, key.name+"\t"+key.recordType+"\t"+ttl+"\t"+key.data+"\n")
+	}
+	sort.Strings(lines)
+	hash := sha256.New()
+	for _, line := range lines {
+		hash.Write([]byte(line))
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,56 @@
+package linode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+func TestZoneFingerprintBypassesCache(t *testing.T) {
+	p, fake, domainID := newTestProvider(t)
+	p.RecordCacheTTL = time.Hour
+	mustAppend(t, p, libdns.TXT{Name: "www", Text: "hello"})
+	mustGet(t, p)
+
+	before, err := p.ZoneFingerprint(context.Background(), testZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A change made outside the provider, which the cache doesn't see.
+	_, err = fake.CreateDomainRecord(context.Background(), domainID, linodego.DomainRecordCreateOptions{
+		Type:   linodego.RecordTypeTXT,
+		Name:   "other",
+		Target: "changed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, err := p.ZoneFingerprint(context.Background(), testZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if before == after {
+		t.Error("fingerprint did not change after the zone was changed outside the provider")
+	}
+}
+
+func TestZoneFingerprintOrder(t *testing.T) {
+	p, _, _ := newTestProvider(t)
+	mustAppend(t, p, libdns.TXT{Name: "a", Text: "1"}, libdns.TXT{Name: "b", Text: "2"})
+	q, _, _ := newTestProvider(t)
+	mustAppend(t, q, libdns.TXT{Name: "b", Text: "2"}, libdns.TXT{Name: "a", Text: "1"})
+	fp, err := p.ZoneFingerprint(context.Background(), testZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fq, err := q.ZoneFingerprint(context.Background(), testZone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fp != fq {
+		t.Errorf("fingerprints of the same records in another order differ: %s != %s", fp, fq)
+	}
+}
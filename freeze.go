@@ -0,0 +1,36 @@
+package linode
+
+import (
+	"time"
+)
+
+// FreezeZone rejects writes to the zone with ErrZoneFrozen until the given
+// time, such as during a maintenance window or cutover, while other zones
+// remain writable. Freezing a frozen zone again replaces the end of the freeze.
+func (p *Provider) FreezeZone(zone string, until time.Time) {
+	p.freezeMutex.Lock()
+	defer p.freezeMutex.Unlock()
+	if p.frozen == nil {
+		p.frozen = make(map[string]time.Time)
+	}
+	p.frozen[zoneKey(zone)] = until
+}
+
+// UnfreezeZone ends the freeze of the zone before its time.
+func (p *Provider) UnfreezeZone(zone string) {
+	p.freezeMutex.Lock()
+	defer p.freezeMutex.Unlock()
+	delete(p.frozen, zoneKey(zone))
+}
+
+// frozenUntil returns the end of the zone's freeze, if it is frozen.
+func (p *Provider) frozenUntil(zone string) (time.Time, bool) {
+	p.freezeMutex.Lock()
+	defer p.freezeMutex.Unlock()
+	until, ok := p.frozen[zoneKey(zone)]
+	if ok && !time.Now().Before(until) {
+		delete(p.frozen, zoneKey(zone))
+		return time.Time{}, false
+	}
+	return until, ok
+}
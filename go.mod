@@ -3,15 +3,17 @@ module github.com/libdns/linode
 go 1.20
 
 require (
+	github.com/go-resty/resty/v2 v2.9.1
 	github.com/libdns/libdns v1.1.0
 	github.com/linode/linodego v1.25.0
+	github.com/miekg/dns v1.1.58
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/go-resty/resty/v2 v2.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	golang.org/x/net v0.17.0 // indirect
+	golang.org/x/net v0.20.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/ini.v1 v1.66.6 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
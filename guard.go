@@ -0,0 +1,96 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libdns/libdns"
+)
+
+type forceContextKey struct{}
+
+// WithForce returns a context that lets writes made with it exceed
+// MaxDeletionsPerCall and MaxDeletionPercent.
+func WithForce(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceContextKey{}, true)
+}
+
+func isForced(ctx context.Context) bool {
+	forced, _ := ctx.Value(forceContextKey{}).(bool)
+	return forced
+}
+
+type dryRunContextKey struct{}
+
+// WithDryRun returns a context that makes AppendRecords, SetRecords,
+// DeleteRecords, ApplyChanges and the operations built on them only plan
+// their writes. They read the zone as usual and return what they would do,
+// with the outcomes the writes would have, but make no changes and don't ask
+// Confirm.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, true)
+}
+
+func isDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunContextKey{}).(bool)
+	return dryRun
+}
+
+type confirmedContextKey struct{}
+
+// withConfirmed returns a context whose writes don't ask Confirm, for writes
+// that undo or complete changes Confirm already allowed.
+func withConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, confirmedContextKey{}, true)
+}
+
+// checkDeletions returns an error wrapping ErrTooManyDeletions if removing
+// this many records from a zone of zoneSize records exceeds the configured
+// limits, unless the context was created with WithForce.
+func (p *Provider) checkDeletions(ctx context.Context, deletions, zoneSize int) error {
+	if deletions == 0 || isForced(ctx) {
+		return nil
+	}
+	if p.MaxDeletionsPerCall > 0 && deletions > p.MaxDeletionsPerCall {
+		return fmt.Errorf("%w: %d records, at most %d are allowed", ErrTooManyDeletions, deletions, p.MaxDeletionsPerCall)
+	}
+	if p.MaxDeletionPercent > 0 && zoneSize > 0 {
+		if percent := float64(deletions) * 100 / float64(zoneSize); percent > p.MaxDeletionPercent {
+			return fmt.Errorf("%w: %.0f%% of the zone, at most %g%% is allowed", ErrTooManyDeletions, percent, p.MaxDeletionPercent)
+		}
+	}
+	return nil
+}
+
+// DestructiveChanges are the changes a call is about to make that remove or
+// replace records, see Provider.Confirm.
+type DestructiveChanges struct {
+	Zone string
+	// Operation is "set", "delete", "apply", "sync", "rename" or
+	// "delete_zone".
+	Operation string
+	// Deletes are the records that will be deleted.
+	Deletes []libdns.Record
+	// ApexChanges are the new values of records at the zone apex that will
+	// be replaced.
+	ApexChanges []libdns.Record
+}
+
+// ConfirmFunc decides whether destructive changes may be applied. It is called
+// while the provider is locked, so it must not call the provider.
+type ConfirmFunc func(ctx context.Context, changes DestructiveChanges) bool
+
+// confirm asks Confirm whether the changes may be applied, and returns
+// ErrNotConfirmed if not. Contexts from withConfirmed are not asked about.
+func (p *Provider) confirm(ctx context.Context, changes DestructiveChanges) error {
+	if p.Confirm == nil || len(changes.Deletes)+len(changes.ApexChanges) == 0 {
+		return nil
+	}
+	if confirmed, _ := ctx.Value(confirmedContextKey{}).(bool); confirmed {
+		return nil
+	}
+	if !p.Confirm(ctx, changes) {
+		return ErrNotConfirmed
+	}
+	return nil
+}
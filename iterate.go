@@ -0,0 +1,67 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+// ForEachRecord calls fn for every record of the zone, fetching one page of
+// records at a time, so that zones with tens of thousands of records need
+// not be held in memory at once. The provider is not locked while fn runs,
+// so fn may use it. If fn returns an error, the iteration stops and the
+// error is returned. Records changed during the iteration may be missed or
+// seen twice, and the record cache is not used.
+func (p *Provider) ForEachRecord(ctx context.Context, zone string, fn func(libdns.Record) error) error {
+	for page := 1; ; page++ {
+		records, pages, err := p.recordPage(ctx, zone, page)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if err := fn(record); err != nil {
+				return err
+			}
+		}
+		if page >= pages {
+			return nil
+		}
+	}
+}
+
+// recordPage returns a page of the records of the zone and the number of pages.
+func (p *Provider) recordPage(ctx context.Context, zone string, page int) ([]libdns.Record, int, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, 0, err
+	}
+	parent, err := p.parentZone(ctx, zone)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx = withZone(ctx, parent)
+	domainID, err := p.getDomainIDByZone(ctx, parent)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not find domain ID for zone: %s: %w", parent, err)
+	}
+	listOptions := linodego.NewListOptions(page, "")
+	listOptions.PageSize = listPageSize
+	linodeRecords, err := p.api.ListDomainRecords(ctx, domainID, listOptions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("could not list domain records: %w", err)
+	}
+	records, err := p.convertRecords(parent, linodeRecords)
+	if err != nil {
+		return nil, 0, err
+	}
+	if parent != zone {
+		records = recordsInZone(records, parent, zone)
+	}
+	return records, listOptions.Pages, nil
+}
@@ -0,0 +1,373 @@
+// Package linodetest helps testing code that uses the linode provider
+// without credentials. Fake is an in-memory fake of the Linode DNS API:
+//
+//	fake := new(linodetest.Fake)
+//	fake.AddDomain("example.com")
+//	provider := &linode.Provider{API: fake}
+//
+// Replayer serves API responses captured with Recorder, to test against
+// what the real API returns.
+package linodetest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/libdns/linode"
+	"github.com/linode/linodego"
+)
+
+// Fake is an in-memory linode.DomainAPI. It keeps domains and their records
+// like the Linode API does, but only filters domain listings by the "domain"
+// field and records no events. The zero value is ready to use, and a Fake is
+// safe for concurrent use.
+type Fake struct {
+	// Grants, if set before use, makes the Fake's user a restricted user
+	// with these grants. Otherwise the user is unrestricted.
+	Grants *linodego.UserGrants
+
+	mutex   sync.Mutex
+	nextID  int
+	domains map[int]*linodego.Domain
+	records map[int][]linodego.DomainRecord
+}
+
+var (
+	_ linode.DomainAPI  = (*Fake)(nil)
+	_ linode.ProfileAPI = (*Fake)(nil)
+)
+
+// AddDomain adds a master domain and returns its ID.
+func (f *Fake) AddDomain(domain string) int {
+	created, err := f.CreateDomain(context.Background(), linodego.DomainCreateOptions{Domain: domain, Type: linodego.DomainTypeMaster})
+	if err != nil {
+		panic(err)
+	}
+	return created.ID
+}
+
+// Records returns the records of the domain.
+func (f *Fake) Records(domainID int) []linodego.DomainRecord {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([]linodego.DomainRecord(nil), f.records[domainID]...)
+}
+
+func (f *Fake) newID() int {
+	f.nextID++
+	return f.nextID
+}
+
+// ListDomains implements linode.DomainAPI.
+func (f *Fake) ListDomains(_ context.Context, opts *linodego.ListOptions) ([]linodego.Domain, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	var filter struct {
+		Domain string `json:"domain"`
+	}
+	if opts != nil && opts.Filter != "" {
+		if err := json.Unmarshal([]byte(opts.Filter), &filter); err != nil {
+			return nil, &linodego.Error{Code: http.StatusBadRequest, Message: fmt.Sprintf("invalid filter: %v", err)}
+		}
+	}
+	domains := []linodego.Domain{}
+	for _, domain := range f.domains {
+		if filter.Domain == "" || strings.EqualFold(domain.Domain, filter.Domain) {
+			domains = append(domains, *domain)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool { return domains[i].ID < domains[j].ID })
+	setResults(opts, len(domains))
+	return domains, nil
+}
+
+// GetDomain implements linode.DomainAPI.
+func (f *Fake) GetDomain(_ context.Context, domainID int) (*linodego.Domain, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	domain, ok := f.domains[domainID]
+	if !ok {
+		return nil, notFound()
+	}
+	copied := *domain
+	return &copied, nil
+}
+
+// CreateDomain implements linode.DomainAPI.
+func (f *Fake) CreateDomain(_ context.Context, opts linodego.DomainCreateOptions) (*linodego.Domain, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if opts.Domain == "" {
+		return nil, &linodego.Error{Code: http.StatusBadRequest, Message: "domain is required"}
+	}
+	for _, domain := range f.domains {
+		if strings.EqualFold(domain.Domain, opts.Domain) {
+			return nil, &linodego.Error{Code: http.StatusBadRequest, Message: "Domain already exists"}
+		}
+	}
+	domain := &linodego.Domain{
+		ID:          f.newID(),
+		Domain:      opts.Domain,
+		Type:        opts.Type,
+		Group:       opts.Group,
+		Status:      opts.Status,
+		Description: opts.Description,
+		SOAEmail:    opts.SOAEmail,
+		RetrySec:    opts.RetrySec,
+		MasterIPs:   opts.MasterIPs,
+		AXfrIPs:     opts.AXfrIPs,
+		Tags:        opts.Tags,
+		ExpireSec:   opts.ExpireSec,
+		RefreshSec:  opts.RefreshSec,
+		TTLSec:      opts.TTLSec,
+	}
+	if domain.Status == "" {
+		domain.Status = linodego.DomainStatus("active")
+	}
+	if f.domains == nil {
+		f.domains = make(map[int]*linodego.Domain)
+		f.records = make(map[int][]linodego.DomainRecord)
+	}
+	f.domains[domain.ID] = domain
+	copied := *domain
+	return &copied, nil
+}
+
+// UpdateDomain implements linode.DomainAPI. As with the Linode API, the IP
+// lists and tags are replaced even when they are empty.
+func (f *Fake) UpdateDomain(_ context.Context, domainID int, opts linodego.DomainUpdateOptions) (*linodego.Domain, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	domain, ok := f.domains[domainID]
+	if !ok {
+		return nil, notFound()
+	}
+	setString(&domain.Domain, opts.Domain)
+	setString((*string)(&domain.Type), string(opts.Type))
+	setString(&domain.Group, opts.Group)
+	setString((*string)(&domain.Status), string(opts.Status))
+	setString(&domain.Description, opts.Description)
+	setString(&domain.SOAEmail, opts.SOAEmail)
+	setInt(&domain.RetrySec, opts.RetrySec)
+	setInt(&domain.ExpireSec, opts.ExpireSec)
+	setInt(&domain.RefreshSec, opts.RefreshSec)
+	setInt(&domain.TTLSec, opts.TTLSec)
+	domain.MasterIPs = opts.MasterIPs
+	domain.AXfrIPs = opts.AXfrIPs
+	domain.Tags = opts.Tags
+	copied := *domain
+	return &copied, nil
+}
+
+// DeleteDomain implements linode.DomainAPI.
+func (f *Fake) DeleteDomain(_ context.Context, domainID int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.domains[domainID]; !ok {
+		return notFound()
+	}
+	delete(f.domains, domainID)
+	delete(f.records, domainID)
+	return nil
+}
+
+// GetDomainZoneFile implements linode.DomainAPI with a zone file of the
+// domain's records, without the SOA record Linode adds.
+func (f *Fake) GetDomainZoneFile(_ context.Context, domainID int) (*linodego.DomainZoneFile, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	domain, ok := f.domains[domainID]
+	if !ok {
+		return nil, notFound()
+	}
+	zoneFile := &linodego.DomainZoneFile{ZoneFile: []string{"$ORIGIN " + domain.Domain + "."}}
+	for _, record := range f.records[domainID] {
+		name := record.Name
+		if name == "" {
+			name = "@"
+		}
+		data := record.Target
+		switch record.Type {
+		case linodego.RecordTypeMX:
+			data = fmt.Sprintf("%d %s", record.Priority, data)
+		case linodego.RecordTypeSRV:
+			data = fmt.Sprintf("%d %d %d %s", record.Priority, record.Weight, record.Port, data)
+		case linodego.RecordTypeTXT:
+			if !strings.HasPrefix(data, `"`) {
+				data = fmt.Sprintf("%q", data)
+			}
+		}
+		zoneFile.ZoneFile = append(zoneFile.ZoneFile, fmt.Sprintf("%s\t%d\tIN\t%s\t%s", name, record.TTLSec, record.Type, data))
+	}
+	return zoneFile, nil
+}
+
+// ListDomainRecords implements linode.DomainAPI.
+func (f *Fake) ListDomainRecords(_ context.Context, domainID int, opts *linodego.ListOptions) ([]linodego.DomainRecord, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.domains[domainID]; !ok {
+		return nil, notFound()
+	}
+	records := append([]linodego.DomainRecord{}, f.records[domainID]...)
+	setResults(opts, len(records))
+	return records, nil
+}
+
+// CreateDomainRecord implements linode.DomainAPI. Like the Linode API, it
+// rejects a record with the same name, type and target as an existing one.
+func (f *Fake) CreateDomainRecord(_ context.Context, domainID int, opts linodego.DomainRecordCreateOptions) (*linodego.DomainRecord, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.domains[domainID]; !ok {
+		return nil, notFound()
+	}
+	record := linodego.DomainRecord{
+		ID:       f.newID(),
+		Type:     opts.Type,
+		Name:     opts.Name,
+		Target:   opts.Target,
+		Service:  opts.Service,
+		Protocol: opts.Protocol,
+		TTLSec:   opts.TTLSec,
+		Tag:      opts.Tag,
+	}
+	setIntPtr(&record.Priority, opts.Priority)
+	setIntPtr(&record.Weight, opts.Weight)
+	setIntPtr(&record.Port, opts.Port)
+	if err := f.checkRecord(domainID, record); err != nil {
+		return nil, err
+	}
+	f.records[domainID] = append(f.records[domainID], record)
+	return &record, nil
+}
+
+// UpdateDomainRecord implements linode.DomainAPI.
+func (f *Fake) UpdateDomainRecord(_ context.Context, domainID, recordID int, opts linodego.DomainRecordUpdateOptions) (*linodego.DomainRecord, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	i := f.recordIndex(domainID, recordID)
+	if i < 0 {
+		return nil, notFound()
+	}
+	record := f.records[domainID][i]
+	setString((*string)(&record.Type), string(opts.Type))
+	setString(&record.Name, opts.Name)
+	setString(&record.Target, opts.Target)
+	setInt(&record.TTLSec, opts.TTLSec)
+	setIntPtr(&record.Priority, opts.Priority)
+	setIntPtr(&record.Weight, opts.Weight)
+	setIntPtr(&record.Port, opts.Port)
+	if opts.Service != nil {
+		record.Service = opts.Service
+	}
+	if opts.Protocol != nil {
+		record.Protocol = opts.Protocol
+	}
+	if opts.Tag != nil {
+		record.Tag = opts.Tag
+	}
+	if err := f.checkRecord(domainID, record); err != nil {
+		return nil, err
+	}
+	f.records[domainID][i] = record
+	return &record, nil
+}
+
+// DeleteDomainRecord implements linode.DomainAPI.
+func (f *Fake) DeleteDomainRecord(_ context.Context, domainID, recordID int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	i := f.recordIndex(domainID, recordID)
+	if i < 0 {
+		return notFound()
+	}
+	records := f.records[domainID]
+	f.records[domainID] = append(records[:i:i], records[i+1:]...)
+	return nil
+}
+
+// ListEvents implements linode.DomainAPI. The fake records no events.
+func (f *Fake) ListEvents(_ context.Context, opts *linodego.ListOptions) ([]linodego.Event, error) {
+	setResults(opts, 0)
+	return nil, nil
+}
+
+// GetProfile implements linode.ProfileAPI. The user is restricted if Grants
+// is set.
+func (f *Fake) GetProfile(context.Context) (*linodego.Profile, error) {
+	return &linodego.Profile{Username: "fake", Restricted: f.Grants != nil}, nil
+}
+
+// GrantsList implements linode.ProfileAPI, returning Grants. Like the Linode
+// API, it returns no grants for unrestricted users.
+func (f *Fake) GrantsList(context.Context) (*linodego.GrantsListResponse, error) {
+	if f.Grants == nil {
+		return &linodego.GrantsListResponse{}, nil
+	}
+	return f.Grants, nil
+}
+
+// recordIndex returns the index of the record in the records of the domain,
+// or -1.
+func (f *Fake) recordIndex(domainID, recordID int) int {
+	for i, record := range f.records[domainID] {
+		if record.ID == recordID {
+			return i
+		}
+	}
+	return -1
+}
+
+// checkRecord returns an error if the record has no type or duplicates
+// another record of the domain.
+func (f *Fake) checkRecord(domainID int, record linodego.DomainRecord) error {
+	if record.Type == "" {
+		return &linodego.Error{Code: http.StatusBadRequest, Message: "type is required"}
+	}
+	for _, other := range f.records[domainID] {
+		if other.ID != record.ID && other.Type == record.Type && strings.EqualFold(other.Name, record.Name) && other.Target == record.Target {
+			return &linodego.Error{Code: http.StatusBadRequest, Message: "Record conflict - Duplicate record"}
+		}
+	}
+	return nil
+}
+
+func notFound() error {
+	return &linodego.Error{Code: http.StatusNotFound, Message: "Not found"}
+}
+
+// setResults fills in the paging fields of the options like a single page
+// response of the Linode API.
+func setResults(opts *linodego.ListOptions, results int) {
+	if opts == nil {
+		return
+	}
+	if opts.PageOptions == nil {
+		opts.PageOptions = &linodego.PageOptions{}
+	}
+	opts.Page, opts.Pages, opts.Results = 1, 1, results
+}
+
+func setString(field *string, value string) {
+	if value != "" {
+		*field = value
+	}
+}
+
+func setInt(field *int, value int) {
+	if value != 0 {
+		*field = value
+	}
+}
+
+func setIntPtr(field *int, value *int) {
+	if value != nil {
+		*field = *value
+	}
+}
@@ -0,0 +1,73 @@
+package linodetest_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+func TestFakeRecords(t *testing.T) {
+	ctx := context.Background()
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("example.com")
+
+	opts := linodego.DomainRecordCreateOptions{Type: linodego.RecordTypeA, Name: "www", Target: "192.0.2.1"}
+	created, err := fake.CreateDomainRecord(ctx, domainID, opts)
+	if err != nil {
+		t.Fatalf("CreateDomainRecord: %v", err)
+	}
+	if _, err := fake.CreateDomainRecord(ctx, domainID, opts); errorCode(err) != http.StatusBadRequest {
+		t.Fatalf("duplicate CreateDomainRecord = %v, want 400", err)
+	}
+	updated, err := fake.UpdateDomainRecord(ctx, domainID, created.ID, linodego.DomainRecordUpdateOptions{Target: "192.0.2.2", TTLSec: 300})
+	if err != nil {
+		t.Fatalf("UpdateDomainRecord: %v", err)
+	}
+	if updated.Target != "192.0.2.2" || updated.TTLSec != 300 || updated.Name != "www" {
+		t.Fatalf("updated record = %+v", updated)
+	}
+
+	listOptions := linodego.NewListOptions(0, "")
+	records, err := fake.ListDomainRecords(ctx, domainID, listOptions)
+	if err != nil {
+		t.Fatalf("ListDomainRecords: %v", err)
+	}
+	if len(records) != 1 || listOptions.Results != 1 {
+		t.Fatalf("listed %d records with %d results, want 1", len(records), listOptions.Results)
+	}
+
+	if err := fake.DeleteDomainRecord(ctx, domainID, created.ID); err != nil {
+		t.Fatalf("DeleteDomainRecord: %v", err)
+	}
+	if err := fake.DeleteDomainRecord(ctx, domainID, created.ID); errorCode(err) != http.StatusNotFound {
+		t.Fatalf("second DeleteDomainRecord = %v, want 404", err)
+	}
+	if _, err := fake.ListDomainRecords(ctx, domainID+1, nil); errorCode(err) != http.StatusNotFound {
+		t.Fatalf("ListDomainRecords of an unknown domain = %v, want 404", err)
+	}
+}
+
+func TestFakeListDomainsFilter(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	fake.AddDomain("example.org")
+	domains, err := fake.ListDomains(context.Background(), linodego.NewListOptions(0, `{"domain": "EXAMPLE.org"}`))
+	if err != nil {
+		t.Fatalf("ListDomains: %v", err)
+	}
+	if len(domains) != 1 || domains[0].Domain != "example.org" {
+		t.Fatalf("ListDomains = %+v, want example.org", domains)
+	}
+}
+
+func errorCode(err error) int {
+	var apiErr *linodego.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Code
+	}
+	return 0
+}
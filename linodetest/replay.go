@@ -0,0 +1,184 @@
+package linodetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+
+	"github.com/libdns/linode"
+)
+
+// Interaction is a request to the Linode API and the response it got, as
+// stored in fixture files.
+type Interaction struct {
+	// Method and URI identify the request, e.g. "GET" and
+	// "/v4/domains/1/records?page=1&page_size=500".
+	Method string `json:"method"`
+	URI    string `json:"uri"`
+	// Status, Header and Body are the response. The Content-Type defaults to
+	// application/json.
+	Status int         `json:"status"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+// LoadInteractions reads a fixture file with a JSON array of interactions,
+// as written by Recorder.Save.
+func LoadInteractions(path string) ([]Interaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err := json.Unmarshal(data, &interactions); err != nil {
+		return nil, fmt.Errorf("could not parse fixture %s: %v", path, err)
+	}
+	return interactions, nil
+}
+
+// Replayer is an HTTP server that answers requests with recorded responses,
+// so that a provider's handling of real API responses, including paging,
+// errors and throttling, can be tested without the Linode API. Requests must
+// arrive in the recorded order; a request that doesn't match the next
+// interaction gets a 500 response and is reported by Err.
+type Replayer struct {
+	// Server is the underlying test server.
+	Server *httptest.Server
+
+	mutex        sync.Mutex
+	interactions []Interaction
+	next         int
+	err          error
+}
+
+// NewReplayer starts a server replaying the interactions.
+func NewReplayer(interactions []Interaction) *Replayer {
+	r := &Replayer{interactions: interactions}
+	r.Server = httptest.NewServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+// Provider returns a provider talking to the replayer.
+func (r *Replayer) Provider() *linode.Provider {
+	return &linode.Provider{APIToken: "replay", APIURL: r.Server.URL, APIVersion: "v4"}
+}
+
+// Remaining returns the number of interactions not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.interactions) - r.next
+}
+
+// Err returns the first request that didn't match the recorded ones.
+func (r *Replayer) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+// Close shuts the server down.
+func (r *Replayer) Close() {
+	r.Server.Close()
+}
+
+func (r *Replayer) serve(w http.ResponseWriter, req *http.Request) {
+	interaction, err := r.take(req)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]any{"errors": []map[string]string{{"reason": err.Error()}}})
+		return
+	}
+	for key, values := range interaction.Header {
+		w.Header()[key] = values
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(interaction.Status)
+	io.WriteString(w, interaction.Body)
+}
+
+// take returns the next interaction if it matches the request.
+func (r *Replayer) take(req *http.Request) (Interaction, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	var err error
+	if r.next >= len(r.interactions) {
+		err = fmt.Errorf("unexpected request %s %s after the last interaction", req.Method, req.URL.RequestURI())
+	} else if next := r.interactions[r.next]; next.Method != req.Method || next.URI != req.URL.RequestURI() {
+		err = fmt.Errorf("unexpected request %s %s, expected %s %s", req.Method, req.URL.RequestURI(), next.Method, next.URI)
+	}
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return Interaction{}, err
+	}
+	r.next++
+	return r.interactions[r.next-1], nil
+}
+
+// Recorder is an http.RoundTripper that records the interactions with the
+// Linode API, to capture fixtures for a Replayer from the live API: set
+// Provider.HTTPClient to &http.Client{Transport: recorder}. Request headers,
+// including the token, are not recorded.
+type Recorder struct {
+	// Transport makes the requests, http.DefaultTransport by default.
+	Transport http.RoundTripper
+
+	mutex        sync.Mutex
+	interactions []Interaction
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rec *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := rec.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	rec.interactions = append(rec.interactions, Interaction{
+		Method: req.Method,
+		URI:    req.URL.RequestURI(),
+		Status: resp.StatusCode,
+		Header: header,
+		Body:   string(body),
+	})
+	return resp, nil
+}
+
+// Interactions returns the interactions recorded so far.
+func (rec *Recorder) Interactions() []Interaction {
+	rec.mutex.Lock()
+	defer rec.mutex.Unlock()
+	return append([]Interaction(nil), rec.interactions...)
+}
+
+// Save writes the recorded interactions to a fixture file.
+func (rec *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(rec.Interactions(), "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,63 @@
+package linodetest_test
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/libdns/linode/linodetest"
+)
+
+func TestRecorderCapturesReplayableFixtures(t *testing.T) {
+	fixture := filepath.Join("..", "testdata", "replay", "get_records.json")
+	interactions, err := linodetest.LoadInteractions(fixture)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer := linodetest.NewReplayer(interactions)
+	defer replayer.Close()
+
+	recorder := new(linodetest.Recorder)
+	provider := replayer.Provider()
+	provider.HTTPClient = &http.Client{Transport: recorder}
+	if _, err := provider.GetRecords(context.Background(), "example.com."); err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	if err := replayer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "recorded.json")
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	recorded, err := linodetest.LoadInteractions(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recorded) != len(interactions) {
+		t.Fatalf("recorded %d interactions, want %d", len(recorded), len(interactions))
+	}
+	for i := range recorded {
+		got, want := recorded[i], interactions[i]
+		if got.Method != want.Method || got.URI != want.URI || got.Status != want.Status || got.Body != want.Body {
+			t.Errorf("interaction %d = %s %s %d, want %s %s %d", i, got.Method, got.URI, got.Status, want.Method, want.URI, want.Status)
+		}
+		if !reflect.DeepEqual(got.Header["Content-Type"], want.Header["Content-Type"]) {
+			t.Errorf("interaction %d has Content-Type %v, want %v", i, got.Header["Content-Type"], want.Header["Content-Type"])
+		}
+	}
+}
+
+func TestReplayerRejectsUnexpectedRequests(t *testing.T) {
+	replayer := linodetest.NewReplayer(nil)
+	defer replayer.Close()
+	if _, err := replayer.Provider().GetRecords(context.Background(), "example.com."); err == nil {
+		t.Fatal("GetRecords succeeded without interactions")
+	}
+	if replayer.Err() == nil {
+		t.Fatal("Err is nil after an unexpected request")
+	}
+}
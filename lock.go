@@ -0,0 +1,235 @@
+package linode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ZoneLocker serializes writes to a zone across instances of an application
+// managing the same zone.
+type ZoneLocker interface {
+	// Lock blocks until the lock of the zone is acquired or the context is
+	// done, and returns a function that releases the lock.
+	Lock(ctx context.Context, zone string) (unlock func(), err error)
+}
+
+// FileZoneLocker is a ZoneLocker using lock files in a directory shared by the
+// instances, such as a volume mounted into every replica. A lock file holds a
+// random token of its owner, so that an instance never releases a lock that
+// was taken over after its own went stale.
+type FileZoneLocker struct {
+	// Dir is the directory holding the lock files.
+	Dir string
+	// PollInterval is how often a held lock is retried, 100ms by default.
+	PollInterval time.Duration
+	// StaleAfter is the age after which a lock file is assumed to belong to a
+	// crashed instance and is removed. Zero disables stale lock removal.
+	StaleAfter time.Duration
+}
+
+// Lock implements ZoneLocker.
+func (l *FileZoneLocker) Lock(ctx context.Context, zone string) (func(), error) {
+	path := filepath.Join(l.Dir, zoneKey(zone)+".lock")
+	interval := l.PollInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	tmp := path + "." + token
+	for {
+		// The lock file is created by linking a new file that already holds
+		// the token, so that it is never seen without it or with an old
+		// modification time.
+		if err := os.WriteFile(tmp, []byte(token), 0o600); err != nil {
+			return nil, fmt.Errorf("could not create lock file: %v", err)
+		}
+		err := os.Link(tmp, path)
+		os.Remove(tmp)
+		if err == nil {
+			return func() {
+				removeLockFile(path, func(owner string, _ time.Time) bool { return owner == token })
+			}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("could not create lock file: %v", err)
+		}
+		if l.StaleAfter > 0 {
+			if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > l.StaleAfter {
+				stale := func(_ string, modified time.Time) bool { return time.Since(modified) > l.StaleAfter }
+				if removeLockFile(path, stale) {
+					continue
+				}
+			}
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// removeLockFile removes the lock file at path if remove reports true for its
+// owner's token and modification time, and reports whether it did. The file
+// is moved aside before it is checked, so that a lock taken after the caller
+// decided to remove the file is never removed; if the moved file is not to be
+// removed, it is put back unless the lock was taken in the meantime.
+func removeLockFile(path string, remove func(owner string, modified time.Time) bool) bool {
+	suffix, err := newLockToken()
+	if err != nil {
+		return false
+	}
+	aside := path + "." + suffix + ".removing"
+	if err := os.Rename(path, aside); err != nil {
+		return false
+	}
+	defer os.Remove(aside)
+	owner, err := os.ReadFile(aside)
+	info, statErr := os.Stat(aside)
+	if err == nil && statErr == nil && remove(string(owner), info.ModTime()) {
+		return true
+	}
+	os.Link(aside, path)
+	return false
+}
+
+// newLockToken returns a random token identifying the owner of a lock.
+func newLockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("could not create lock token: %v", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// RedisClient is the part of a Redis client that RedisZoneLocker uses. A
+// go-redis client is adapted with:
+//
+//	type redisClient struct{ *redis.Client }
+//
+//	func (c redisClient) SetNX(ctx context.Context, key, value string, expiry time.Duration) (bool, error) {
+//		return c.Client.SetNX(ctx, key, value, expiry).Result()
+//	}
+//
+//	func (c redisClient) Eval(ctx context.Context, script string, keys []string, args ...any) (any, error) {
+//		return c.Client.Eval(ctx, script, keys, args...).Result()
+//	}
+type RedisClient interface {
+	// SetNX sets the key to the value with the expiry, unless the key exists,
+	// and reports whether it was set.
+	SetNX(ctx context.Context, key, value string, expiry time.Duration) (bool, error)
+	// Eval runs a Lua script with the keys and arguments.
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// redisUnlockScript deletes the lock key only if it still holds the token of
+// the owner releasing it.
+const redisUnlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+// RedisZoneLocker is a ZoneLocker using keys in a Redis server shared by the
+// instances. A lock is a key holding a random token of its owner, set with
+// SET NX and an expiry, so that the lock of a crashed instance is released,
+// and deleted on unlock only if it still holds the owner's token.
+type RedisZoneLocker struct {
+	Client RedisClient
+	// Prefix is prepended to the zone to form the key of its lock,
+	// "linode-dns/lock/" by default.
+	Prefix string
+	// Expiry is how long a lock is held if it is not released, 1 minute by
+	// default. It must be longer than the writes to a zone take.
+	Expiry time.Duration
+	// PollInterval is how often a held lock is retried, 100ms by default.
+	PollInterval time.Duration
+}
+
+// Lock implements ZoneLocker.
+func (l *RedisZoneLocker) Lock(ctx context.Context, zone string) (func(), error) {
+	prefix := l.Prefix
+	if prefix == "" {
+		prefix = "linode-dns/lock/"
+	}
+	key := prefix + zoneKey(zone)
+	expiry := l.Expiry
+	if expiry <= 0 {
+		expiry = time.Minute
+	}
+	interval := l.PollInterval
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		ok, err := l.Client.SetNX(ctx, key, token, expiry)
+		if err != nil {
+			return nil, fmt.Errorf("could not set lock key: %v", err)
+		}
+		if ok {
+			return func() {
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+				defer cancel()
+				l.Client.Eval(ctx, redisUnlockScript, []string{key}, token)
+			}, nil
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// lockZone locks the zone within the provider, and acquires its distributed
+// lock if a ZoneLocker is configured. The caller must hold p.mutex. It is
+// released while waiting for another call to unlock the zone, since that call
+// takes p.mutex again after its writes on the worker pool, see forEach.
+func (p *Provider) lockZone(ctx context.Context, zone string) (func(), error) {
+	if p.zoneLocks == nil {
+		p.zoneLocks = make(map[string]chan struct{})
+	}
+	key := zoneKey(zone)
+	zoneLock, ok := p.zoneLocks[key]
+	if !ok {
+		zoneLock = make(chan struct{}, 1)
+		p.zoneLocks[key] = zoneLock
+	}
+	select {
+	case zoneLock <- struct{}{}:
+	default:
+		p.mutex.Unlock()
+		select {
+		case zoneLock <- struct{}{}:
+		case <-ctx.Done():
+			p.mutex.Lock()
+			return nil, fmt.Errorf("could not lock zone %s: %v", zone, ctx.Err())
+		}
+		p.mutex.Lock()
+	}
+	if p.ZoneLocker == nil {
+		return func() { <-zoneLock }, nil
+	}
+	unlock, err := p.ZoneLocker.Lock(ctx, zone)
+	if err != nil {
+		<-zoneLock
+		return nil, fmt.Errorf("could not lock zone %s: %v", zone, err)
+	}
+	return func() {
+		unlock()
+		<-zoneLock
+	}, nil
+}
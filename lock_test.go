@@ -0,0 +1,109 @@
+package linode_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/linode"
+)
+
+// assertLocked fails the test unless the zone is locked by another owner.
+func assertLocked(t *testing.T, locker linode.ZoneLocker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if unlock, err := locker.Lock(ctx, testZone); err == nil {
+		unlock()
+		t.Fatal("the zone was not locked")
+	}
+}
+
+func mustLock(t *testing.T, locker linode.ZoneLocker) func() {
+	t.Helper()
+	unlock, err := locker.Lock(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	return unlock
+}
+
+func TestFileZoneLocker(t *testing.T) {
+	locker := &linode.FileZoneLocker{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond}
+	unlock := mustLock(t, locker)
+	assertLocked(t, locker)
+	unlock()
+	mustLock(t, locker)()
+}
+
+func TestFileZoneLockerKeepsTakenOverLock(t *testing.T) {
+	locker := &linode.FileZoneLocker{Dir: t.TempDir(), PollInterval: 5 * time.Millisecond, StaleAfter: 20 * time.Millisecond}
+	unlockStale := mustLock(t, locker)
+	time.Sleep(30 * time.Millisecond)
+	// The stale lock is taken over, and releasing it must not release the
+	// new owner's lock.
+	unlock := mustLock(t, locker)
+	defer unlock()
+	unlockStale()
+	locker.StaleAfter = 0
+	assertLocked(t, locker)
+}
+
+// fakeRedis is a RedisClient for the keys and unlock script of
+// RedisZoneLocker.
+type fakeRedis struct {
+	mutex   sync.Mutex
+	values  map[string]string
+	expires map[string]time.Time
+}
+
+func (r *fakeRedis) get(key string) (string, bool) {
+	if time.Now().After(r.expires[key]) {
+		delete(r.values, key)
+	}
+	value, ok := r.values[key]
+	return value, ok
+}
+
+func (r *fakeRedis) SetNX(_ context.Context, key, value string, expiry time.Duration) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.get(key); ok {
+		return false, nil
+	}
+	if r.values == nil {
+		r.values, r.expires = make(map[string]string), make(map[string]time.Time)
+	}
+	r.values[key], r.expires[key] = value, time.Now().Add(expiry)
+	return true, nil
+}
+
+func (r *fakeRedis) Eval(_ context.Context, _ string, keys []string, args ...any) (any, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if value, ok := r.get(keys[0]); ok && value == args[0] {
+		delete(r.values, keys[0])
+		return int64(1), nil
+	}
+	return int64(0), nil
+}
+
+func TestRedisZoneLocker(t *testing.T) {
+	locker := &linode.RedisZoneLocker{Client: new(fakeRedis), PollInterval: 5 * time.Millisecond}
+	unlock := mustLock(t, locker)
+	assertLocked(t, locker)
+	unlock()
+	mustLock(t, locker)()
+}
+
+func TestRedisZoneLockerKeepsExpiredLock(t *testing.T) {
+	locker := &linode.RedisZoneLocker{Client: new(fakeRedis), PollInterval: 5 * time.Millisecond, Expiry: 20 * time.Millisecond}
+	unlockExpired := mustLock(t, locker)
+	time.Sleep(30 * time.Millisecond)
+	locker.Expiry = time.Hour
+	unlock := mustLock(t, locker)
+	defer unlock()
+	unlockExpired()
+	assertLocked(t, locker)
+}
@@ -0,0 +1,50 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// Logger receives a log entry for every Linode API call, with the attributes
+// "zone", "operation", "name" and "type" of the record, "duration", "status"
+// and, for failed calls, "error". A *slog.Logger can be used directly.
+type Logger interface {
+	DebugContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
+}
+
+type recordContextKey struct{}
+
+// withRecord records the record an API call is made for in the context, so
+// that it can be logged.
+func withRecord(ctx context.Context, record libdns.Record) context.Context {
+	return context.WithValue(ctx, recordContextKey{}, record)
+}
+
+// logAPICall logs an API call to Logger, if set.
+func (p *Provider) logAPICall(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+	if p.Logger == nil {
+		return
+	}
+	ctx := req.Context()
+	args := []any{"operation", req.Method + " " + apiOperation(req.URL.Path)}
+	if zone, ok := zoneFromContext(ctx); ok {
+		args = append(args, "zone", zone)
+	}
+	if record, ok := ctx.Value(recordContextKey{}).(libdns.Record); ok {
+		rr := record.RR()
+		args = append(args, "name", rr.Name, "type", rr.Type)
+	}
+	args = append(args, "duration", duration)
+	switch {
+	case err != nil:
+		p.Logger.ErrorContext(ctx, "Linode API call failed", append(args, "error", err)...)
+	case resp.StatusCode >= 400:
+		p.Logger.ErrorContext(ctx, "Linode API call failed", append(args, "status", resp.StatusCode)...)
+	default:
+		p.Logger.DebugContext(ctx, "Linode API call", append(args, "status", resp.StatusCode)...)
+	}
+}
@@ -0,0 +1,106 @@
+package linode
+
+import (
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// recordKey identifies a record by its name, type and data, ignoring its TTL.
+type recordKey struct {
+	name       string
+	recordType string
+	data       string
+}
+
+func newRecordKey(zone string, record libdns.Record) recordKey {
+	rr := record.RR()
+	key := recordKey{
+		name:       normalizeName(zone, rr.Name),
+		recordType: strings.ToUpper(rr.Type),
+		data:       strings.TrimSuffix(rr.Data, "."),
+	}
+	if key.recordType == "TXT" {
+		key.data = canonicalTXT(rr.Data)
+	}
+	return key
+}
+
+// rrsetKey identifies the RRset of a record by its name and type.
+type rrsetKey struct {
+	name       string
+	recordType string
+}
+
+func newRRSetKey(zone string, record libdns.Record) rrsetKey {
+	rr := record.RR()
+	return rrsetKey{
+		name:       normalizeName(zone, rr.Name),
+		recordType: strings.ToUpper(rr.Type),
+	}
+}
+
+// normalizeName returns the name relative to the zone in lower case, using "@"
+// for the zone apex.
+func normalizeName(zone, name string) string {
+	name = relativeName(name, zone)
+	if name == "" {
+		name = "@"
+	}
+	return strings.ToLower(name)
+}
+
+// matchesDeletion reports whether candidate is deleted by a deletion of
+// record. As in libdns, an empty type or data and a zero TTL of the record
+// match any value.
+func matchesDeletion(zone string, record, candidate libdns.Record) bool {
+	rr, candidateRR := record.RR(), candidate.RR()
+	switch {
+	case normalizeName(zone, rr.Name) != normalizeName(zone, candidateRR.Name):
+		return false
+	case rr.Type != "" && !strings.EqualFold(rr.Type, candidateRR.Type):
+		return false
+	case rr.Data != "" && newRecordKey(zone, record).data != newRecordKey(zone, candidate).data:
+		return false
+	case rr.TTL != 0 && rr.TTL != candidateRR.TTL:
+		return false
+	}
+	return true
+}
+
+// diffRecords compares the live records of a zone with the desired ones and
+// returns the desired records missing from the zone and the live records that
+// are not desired. Desired TTLs are compared the way Linode stores them, with
+// the zone's default TTL for zero TTLs, as in planSet; a desired record whose
+// TTL is still zero matches any TTL.
+func (p *Provider) diffRecords(zone string, live, desired []libdns.Record) (missing, unexpected []libdns.Record) {
+	unmatched := make(map[recordKey][]libdns.Record, len(live))
+	for _, record := range live {
+		key := newRecordKey(zone, record)
+		unmatched[key] = append(unmatched[key], record)
+	}
+	for _, record := range desired {
+		key := newRecordKey(zone, record)
+		ttl := p.ttlDuration(zone, record.RR().TTL)
+		candidates := unmatched[key]
+		found := false
+		for i, candidate := range candidates {
+			if ttl == 0 || candidate.RR().TTL == roundTTL(ttl) {
+				unmatched[key] = append(candidates[:i:i], candidates[i+1:]...)
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, record)
+		}
+	}
+	for _, record := range live {
+		key := newRecordKey(zone, record)
+		if len(unmatched[key]) > 0 {
+			unexpected = append(unexpected, unmatched[key][0])
+			unmatched[key] = unmatched[key][1:]
+		}
+	}
+	return missing, unexpected
+}
@@ -0,0 +1,70 @@
+package linode
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Metrics receives measurements of the provider's Linode API calls, for
+// example to feed Prometheus counters and histograms. Its methods are called
+// concurrently.
+type Metrics interface {
+	// APICall is called after every API request, including retries.
+	APICall(call APICall)
+	// RateLimitWait is called when the client-side rate limiter delays a
+	// request for the zone, which is empty for requests not made for a zone.
+	RateLimitWait(zone string, wait time.Duration)
+}
+
+// APICall describes a single Linode API request.
+type APICall struct {
+	// Operation is the method and path of the request, such as
+	// "GET domains/{id}/records".
+	Operation string
+	// Zone is the zone the request was made for, if any.
+	Zone string
+	// Retry reports whether the request retried an earlier one, such as
+	// after the API responded with 429 Too Many Requests.
+	Retry bool
+	// Status is the HTTP status of the response, or 0 if there was none.
+	Status   int
+	Duration time.Duration
+	// Err is the error that prevented a response, if any.
+	Err error
+}
+
+type retryContextKey struct{}
+
+// withRetry marks the request made with the context as a retry.
+func withRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryContextKey{}, true)
+}
+
+// measureAPICall reports an API call to Metrics, if set.
+func (p *Provider) measureAPICall(req *http.Request, resp *http.Response, duration time.Duration, err error) {
+	if p.Metrics == nil {
+		return
+	}
+	ctx := req.Context()
+	call := APICall{
+		Operation: req.Method + " " + apiOperation(req.URL.Path),
+		Duration:  duration,
+		Err:       err,
+	}
+	call.Zone, _ = zoneFromContext(ctx)
+	call.Retry, _ = ctx.Value(retryContextKey{}).(bool)
+	if resp != nil {
+		call.Status = resp.StatusCode
+	}
+	p.Metrics.APICall(call)
+}
+
+// measureRateLimitWait reports a delay of the rate limiter to Metrics, if set.
+func (p *Provider) measureRateLimitWait(ctx context.Context, wait time.Duration) {
+	if p.Metrics == nil {
+		return
+	}
+	zone, _ := zoneFromContext(ctx)
+	p.Metrics.RateLimitWait(zone, wait)
+}
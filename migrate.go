@@ -0,0 +1,164 @@
+package linode
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+)
+
+// route53RecordSets is the output of "aws route53 list-resource-record-sets".
+type route53RecordSets struct {
+	ResourceRecordSets []struct {
+		Name            string `json:"Name"`
+		Type            string `json:"Type"`
+		TTL             int    `json:"TTL"`
+		ResourceRecords []struct {
+			Value string `json:"Value"`
+		} `json:"ResourceRecords"`
+		AliasTarget *struct {
+			DNSName string `json:"DNSName"`
+		} `json:"AliasTarget"`
+	} `json:"ResourceRecordSets"`
+}
+
+// ParseRoute53 converts the JSON output of "aws route53
+// list-resource-record-sets" for the zone into records. The SOA and apex NS
+// records are skipped, since Linode manages them. SPF records, a type Linode
+// doesn't support and that RFC 7208 replaced with TXT, become TXT records,
+// unless the name already has a TXT record with the same value. Alias records
+// have no equivalent on Linode and cause an error.
+func ParseRoute53(r io.Reader, zone string) ([]libdns.Record, error) {
+	var sets route53RecordSets
+	if err := json.NewDecoder(r).Decode(&sets); err != nil {
+		return nil, fmt.Errorf("could not parse Route 53 record sets: %v", err)
+	}
+	type txtKey struct{ name, text string }
+	txts := make(map[txtKey]bool)
+	for _, set := range sets.ResourceRecordSets {
+		if set.Type == "TXT" {
+			name := libdns.RelativeName(unescapeRoute53Name(set.Name), zone)
+			for _, value := range set.ResourceRecords {
+				txts[txtKey{name, canonicalTXT(value.Value)}] = true
+			}
+		}
+	}
+	var records []libdns.Record
+	for _, set := range sets.ResourceRecordSets {
+		name := libdns.RelativeName(unescapeRoute53Name(set.Name), zone)
+		if set.Type == "SOA" || (set.Type == "NS" && name == "@") {
+			continue
+		}
+		if set.AliasTarget != nil {
+			return nil, fmt.Errorf("%s %s is an alias to %s, which Linode does not support", set.Name, set.Type, set.AliasTarget.DNSName)
+		}
+		for _, value := range set.ResourceRecords {
+			recordType, data := set.Type, value.Value
+			switch set.Type {
+			case "TXT":
+				data = canonicalTXT(data)
+			case "SPF":
+				recordType, data = "TXT", canonicalTXT(data)
+				if txts[txtKey{name, data}] {
+					continue
+				}
+				txts[txtKey{name, data}] = true
+			}
+			record, err := libdns.RR{
+				Name: name,
+				TTL:  time.Duration(set.TTL) * time.Second,
+				Type: recordType,
+				Data: data,
+			}.Parse()
+			if err != nil {
+				return nil, fmt.Errorf("could not parse %s %s: %v", set.Name, set.Type, err)
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// unescapeRoute53Name decodes the octal escapes Route 53 uses in names, such as
+// "\052" for a wildcard.
+func unescapeRoute53Name(name string) string {
+	var b strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] == '\\' && i+3 < len(name) {
+			if c, err := strconv.ParseUint(name[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(c))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(name[i])
+	}
+	return b.String()
+}
+
+// ParseCloudflare converts a zone file exported from Cloudflare into records.
+// It handles the quirks of those exports: the SOA and Cloudflare's apex NS
+// records are skipped, and TTLs of 1 second, which mean "automatic", are
+// replaced with 0 so that Linode's default TTL applies.
+func ParseCloudflare(r io.Reader, zone string) ([]libdns.Record, error) {
+	records, err := parseZoneFile(r, zone)
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]libdns.Record, 0, len(records))
+	for _, record := range records {
+		rr := record.RR()
+		if rr.Type == "NS" && rr.Name == "@" && strings.HasSuffix(strings.TrimSuffix(rr.Data, "."), ".ns.cloudflare.com") {
+			continue
+		}
+		if rr.TTL == time.Second {
+			rr.TTL = 0
+			if record, err = rr.Parse(); err != nil {
+				return nil, err
+			}
+		}
+		converted = append(converted, record)
+	}
+	return converted, nil
+}
+
+// parseZoneFile reads the records of the zone from a zone file in the standard
+// (BIND) format, skipping the SOA record.
+func parseZoneFile(r io.Reader, zone string) ([]libdns.Record, error) {
+	parser := dns.NewZoneParser(r, dns.Fqdn(zone), "")
+	var records []libdns.Record
+	for rr, ok := parser.Next(); ok; rr, ok = parser.Next() {
+		if record, ok := convertFromDNSRR(zone, rr); ok {
+			records = append(records, record)
+		}
+	}
+	if err := parser.Err(); err != nil {
+		return nil, fmt.Errorf("could not parse zone file: %v", err)
+	}
+	return records, nil
+}
+
+// ImportRoute53 adds the records of a Route 53 export to the zone, see ParseRoute53.
+func (p *Provider) ImportRoute53(ctx context.Context, zone string, r io.Reader) ([]RecordResult, error) {
+	records, err := ParseRoute53(r, zone)
+	if err != nil {
+		return nil, err
+	}
+	return p.AppendRecordsWithResults(ctx, zone, records)
+}
+
+// ImportCloudflare adds the records of a Cloudflare zone file export to the
+// zone, see ParseCloudflare.
+func (p *Provider) ImportCloudflare(ctx context.Context, zone string, r io.Reader) ([]RecordResult, error) {
+	records, err := ParseCloudflare(r, zone)
+	if err != nil {
+		return nil, err
+	}
+	return p.AppendRecordsWithResults(ctx, zone, records)
+}
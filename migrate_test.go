@@ -0,0 +1,38 @@
+package linode_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/libdns/linode"
+)
+
+func TestParseRoute53(t *testing.T) {
+	export := `{"ResourceRecordSets": [
+		{"Name": "example.com.", "Type": "SOA", "TTL": 900, "ResourceRecords": [{"Value": "ns-1.awsdns-00.com. awsdns-hostmaster.amazon.com. 1 7200 900 1209600 86400"}]},
+		{"Name": "example.com.", "Type": "TXT", "TTL": 300, "ResourceRecords": [{"Value": "\"v=spf1 -all\""}]},
+		{"Name": "example.com.", "Type": "SPF", "TTL": 300, "ResourceRecords": [{"Value": "\"v=spf1 -all\""}]},
+		{"Name": "mail.example.com.", "Type": "SPF", "TTL": 300, "ResourceRecords": [{"Value": "\"v=spf1 \" \"mx -all\""}]},
+		{"Name": "dkim._domainkey.example.com.", "Type": "TXT", "TTL": 300, "ResourceRecords": [{"Value": "\"v=DKIM1; k=rsa; \" \"p=MIGf\""}]},
+		{"Name": "\\052.example.com.", "Type": "A", "TTL": 60, "ResourceRecords": [{"Value": "192.0.2.1"}]}
+	]}`
+	records, err := linode.ParseRoute53(strings.NewReader(export), testZone)
+	if err != nil {
+		t.Fatalf("ParseRoute53: %v", err)
+	}
+	var got []string
+	for _, record := range records {
+		rr := record.RR()
+		got = append(got, fmt.Sprintf("%s %s %s", rr.Name, rr.Type, rr.Data))
+	}
+	want := []string{
+		"@ TXT v=spf1 -all",
+		"mail TXT v=spf1 mx -all",
+		"dkim._domainkey TXT v=DKIM1; k=rsa; p=MIGf",
+		"* A 192.0.2.1",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("records = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+// GetRecordsMulti lists the records of several zones like GetRecords does.
+// The domains are looked up with a single listing, and the zones are fetched
+// on the worker pool, see Concurrency. It returns the records of the zones
+// that could be read, along with an error for those that could not.
+func (p *Provider) GetRecordsMulti(ctx context.Context, zones []string) (map[string][]libdns.Record, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	var errs []error
+	parents := make(map[string]string, len(zones))
+	read := make(map[string][]libdns.Record)
+	var pending []string
+	for _, zone := range zones {
+		parent, err := p.parentZone(ctx, zone)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", zone, err))
+			continue
+		}
+		parents[zone] = parent
+		key := zoneKey(parent)
+		if _, ok := read[key]; ok {
+			continue
+		}
+		read[key] = nil
+		if records, ok := p.cachedRecords(parent); ok {
+			read[key] = records
+		} else {
+			pending = append(pending, parent)
+		}
+	}
+	domainIDs, err := p.getDomainIDsByZones(ctx, pending)
+	if err != nil {
+		return nil, err
+	}
+	fetched := make([][]libdns.Record, len(pending))
+	fetchErrs := make([]error, len(pending))
+	_ = p.forEach(ctx, len(pending), func(i int) error {
+		zone := pending[i]
+		domainID, ok := domainIDs[zoneKey(zone)]
+		if !ok {
+			fetchErrs[i] = fmt.Errorf("could not find domain ID for zone: %s: %w: %s", zone, ErrZoneNotFound, zone)
+			return nil
+		}
+		fetched[i], fetchErrs[i] = p.readDomainRecords(withZone(ctx, zone), zone, domainID)
+		return nil
+	})
+	readErrs := make(map[string]error)
+	for i, zone := range pending {
+		key := zoneKey(zone)
+		if err := fetchErrs[i]; err != nil {
+			if errors.Is(err, ErrZoneNotFound) {
+				p.uncacheDomainID(zone)
+			}
+			if records, ok := p.staleRecords(ctx, zone, err); ok {
+				read[key] = records
+			} else {
+				readErrs[key] = err
+			}
+			continue
+		}
+		p.cacheRecords(zone, fetched[i])
+		read[key] = fetched[i]
+	}
+	results := make(map[string][]libdns.Record, len(zones))
+	for _, zone := range zones {
+		parent, ok := parents[zone]
+		if !ok {
+			continue
+		}
+		if err := readErrs[zoneKey(parent)]; err != nil {
+			errs = append(errs, fmt.Errorf("zone %s: %w", zone, err))
+			continue
+		}
+		records := read[zoneKey(parent)]
+		if parent != zone {
+			records = recordsInZone(records, parent, zone)
+		}
+		results[zone] = records
+	}
+	return results, errors.Join(errs...)
+}
+
+// getDomainIDsByZones returns the domain IDs of the zones by their zoneKey,
+// listing all domains once unless every ID is cached.
+func (p *Provider) getDomainIDsByZones(ctx context.Context, zones []string) (map[string]int, error) {
+	ids := make(map[string]int, len(zones))
+	missing := false
+	for _, zone := range zones {
+		if id, ok := p.cachedDomainID(zone); ok {
+			ids[zoneKey(zone)] = id
+		} else {
+			missing = true
+		}
+	}
+	if !missing {
+		return ids, nil
+	}
+	listOptions := linodego.NewListOptions(0, "")
+	listOptions.PageSize = listPageSize
+	domains, err := p.api.ListDomains(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not list domains: %w", err)
+	}
+	wanted := make(map[string]bool, len(zones))
+	for _, zone := range zones {
+		wanted[zoneKey(zone)] = true
+	}
+	matches := make(map[string][]linodego.Domain)
+	for _, domain := range domains {
+		key := zoneKey(domain.Domain)
+		if wanted[key] {
+			matches[key] = append(matches[key], domain)
+		}
+	}
+	for key, candidates := range matches {
+		if len(candidates) > 1 {
+			ambiguous := &AmbiguousDomainError{Zone: key}
+			for _, domain := range candidates {
+				ambiguous.Candidates = append(ambiguous.Candidates, DomainCandidate{ID: domain.ID, Status: domain.Status})
+			}
+			return nil, ambiguous
+		}
+		ids[key] = candidates[0].ID
+		p.cacheDomainID(key, candidates[0].ID)
+	}
+	return ids, nil
+}
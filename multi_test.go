@@ -0,0 +1,42 @@
+package linode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+func TestGetRecordsMultiReadsLikeGetRecords(t *testing.T) {
+	fake := new(linodetest.Fake)
+	_, err := fake.CreateDomain(context.Background(), linodego.DomainCreateOptions{Domain: "example.com", Type: linodego.DomainTypeMaster, TTLSec: 3600})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &linode.Provider{
+		API:               fake,
+		ResolveDefaultTTL: true,
+		RecordCacheTTL:    time.Hour,
+		FindParentZone:    true,
+	}
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"), mustParse(t, "host.sub", "A", "192.0.2.2"))
+
+	zones, err := provider.GetRecordsMulti(context.Background(), []string{testZone, "sub.example.com."})
+	if err != nil {
+		t.Fatalf("GetRecordsMulti: %v", err)
+	}
+	if records := zones[testZone]; len(records) != 2 || records[0].RR().TTL != time.Hour {
+		t.Fatalf("records of %s = %v, want 2 records with a TTL of 1h", testZone, records)
+	}
+	if records := zones["sub.example.com."]; len(records) != 1 || records[0].RR().Name != "host" {
+		t.Fatalf("records of sub.example.com. = %v, want host", records)
+	}
+	for _, record := range mustGet(t, provider) {
+		if ttl := record.RR().TTL; ttl != time.Hour {
+			t.Errorf("cached %s has TTL %s, want 1h", record.RR().Name, ttl)
+		}
+	}
+}
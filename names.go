@@ -0,0 +1,52 @@
+package linode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// relativeName returns the name relative to the zone, like libdns.RelativeName,
+// but also corrects names that were given with the zone appended more than
+// once, such as "www.example.com.example.com" for the zone "example.com.".
+func relativeName(name, zone string) string {
+	rel := libdns.RelativeName(name, zone)
+	for {
+		trimmed, ok := trimZoneSuffix(rel, zone)
+		if !ok {
+			return rel
+		}
+		rel = trimmed
+	}
+}
+
+// trimZoneSuffix removes the zone from the end of a relative name that still
+// ends with it, and reports whether it did.
+func trimZoneSuffix(name, zone string) (string, bool) {
+	suffix := "." + strings.ToLower(strings.TrimSuffix(zone, "."))
+	if len(suffix) == 1 || !strings.HasSuffix(strings.ToLower(name), suffix) {
+		return name, false
+	}
+	return name[:len(name)-len(suffix)], true
+}
+
+// repeatsZone reports whether the name contains the zone suffix more than once.
+func repeatsZone(name, zone string) bool {
+	_, ok := trimZoneSuffix(libdns.RelativeName(name, zone), zone)
+	return ok
+}
+
+// checkNames returns an error for the first record whose name repeats the zone
+// suffix, if StrictNames is set. Otherwise such names are corrected.
+func (p *Provider) checkNames(zone string, records []libdns.Record) error {
+	if !p.StrictNames {
+		return nil
+	}
+	for i, record := range records {
+		if name := record.RR().Name; repeatsZone(name, zone) {
+			return &RecordError{Index: i, Record: record, Err: fmt.Errorf("%w: name %q repeats the zone %s", ErrInvalidRecord, name, zone)}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package linode
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+)
+
+// Option configures a Provider created with NewProvider.
+type Option func(*Provider)
+
+// NewProvider returns a provider using the API token, configured by the
+// options. Unlike a Provider literal, whose mistakes surface on first use,
+// its configuration is checked with Validate before it is returned. An
+// empty token falls back to LINODE_TOKEN, as for APIToken.
+func NewProvider(token string, opts ...Option) (*Provider, error) {
+	p := &Provider{APIToken: token}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithAPIURL sets APIURL.
+func WithAPIURL(apiURL string) Option {
+	return func(p *Provider) { p.APIURL = apiURL }
+}
+
+// WithHTTPClient sets HTTPClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) { p.HTTPClient = client }
+}
+
+// WithDefaultTTL sets DefaultTTL.
+func WithDefaultTTL(ttl time.Duration) Option {
+	return func(p *Provider) { p.DefaultTTL = ttl }
+}
+
+// WithLogger sets Logger.
+func WithLogger(logger Logger) Option {
+	return func(p *Provider) { p.Logger = logger }
+}
+
+// WithMetrics sets Metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(p *Provider) { p.Metrics = metrics }
+}
+
+// WithTracer sets Tracer.
+func WithTracer(tracer Tracer) Option {
+	return func(p *Provider) { p.Tracer = tracer }
+}
+
+// WithRetries sets MaxRetries, RetryWait and RetryMaxWait.
+func WithRetries(maxRetries int, wait, maxWait time.Duration) Option {
+	return func(p *Provider) {
+		p.MaxRetries, p.RetryWait, p.RetryMaxWait = maxRetries, wait, maxWait
+	}
+}
+
+// WithRateLimit sets RateLimit and RateLimitWindow.
+func WithRateLimit(requests int, window time.Duration) Option {
+	return func(p *Provider) { p.RateLimit, p.RateLimitWindow = requests, window }
+}
+
+// WithConcurrency sets Concurrency.
+func WithConcurrency(workers int) Option {
+	return func(p *Provider) { p.Concurrency = workers }
+}
+
+// WithRecordCache sets RecordCacheTTL.
+func WithRecordCache(ttl time.Duration) Option {
+	return func(p *Provider) { p.RecordCacheTTL = ttl }
+}
+
+// WithUserAgent sets UserAgent.
+func WithUserAgent(userAgent string) Option {
+	return func(p *Provider) { p.UserAgent = userAgent }
+}
+
+// Validate checks the configuration of the provider, including that the
+// files it references can be loaded, and returns the first problem found.
+func (p *Provider) Validate() error {
+	if p.API == nil && p.TokenSource == nil && p.apiToken() == "" {
+		return errors.New("no API token configured: set APIToken, TokenSource or LINODE_TOKEN")
+	}
+	if apiURL := p.apiURL(); apiURL != "" {
+		if _, err := url.Parse(apiURL); err != nil {
+			return fmt.Errorf("invalid API URL: %v", err)
+		}
+	}
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"DefaultTTL", p.DefaultTTL},
+		{"RecordCacheTTL", p.RecordCacheTTL},
+		{"StaleWhileRevalidate", p.StaleWhileRevalidate},
+		{"DomainIDCacheTTL", p.DomainIDCacheTTL},
+		{"RateLimitWindow", p.RateLimitWindow},
+		{"RetryWait", p.RetryWait},
+		{"RetryMaxWait", p.RetryMaxWait},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			return fmt.Errorf("%s must not be negative", d.name)
+		}
+	}
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"Concurrency", p.Concurrency},
+		{"RateLimit", p.RateLimit},
+		{"RateLimitHeadroom", p.RateLimitHeadroom},
+		{"MaxRetries", p.MaxRetries},
+		{"MaxDeletionsPerCall", p.MaxDeletionsPerCall},
+	}
+	for _, c := range counts {
+		if c.value < 0 {
+			return fmt.Errorf("%s must not be negative", c.name)
+		}
+	}
+	if p.DomainID > 0 && zoneKey(p.DomainZone) == "" {
+		return errors.New("DomainID requires DomainZone, the zone of the domain")
+	}
+	if p.MaxDeletionPercent < 0 || p.MaxDeletionPercent > 100 {
+		return errors.New("MaxDeletionPercent must be between 0 and 100")
+	}
+	for _, pattern := range p.AllowedNames {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q in AllowedNames: %v", pattern, err)
+		}
+	}
+	_, err := p.httpClient()
+	return err
+}
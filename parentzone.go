@@ -0,0 +1,100 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// parentZone returns the zone of the Linode domain that holds the records of
+// the zone. With FindParentZone, a zone that is not a domain itself, such as
+// "sub.deep.example.com.", is resolved to the closest enclosing domain, such
+// as "example.com.". Otherwise, and if no enclosing domain is found, it is
+// the zone itself.
+func (p *Provider) parentZone(ctx context.Context, zone string) (string, error) {
+	if !p.FindParentZone {
+		return zone, nil
+	}
+	if parent, ok := p.parentZones[zoneKey(zone)]; ok {
+		return parent, nil
+	}
+	labels := strings.Split(strings.TrimSuffix(zone, "."), ".")
+	for i := 0; i < len(labels)-1; i++ {
+		candidate := strings.Join(labels[i:], ".")
+		if strings.HasSuffix(zone, ".") {
+			candidate += "."
+		}
+		_, err := p.getDomainIDByZone(withZone(ctx, candidate), candidate)
+		if errors.Is(err, ErrZoneNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if p.parentZones == nil {
+			p.parentZones = make(map[string]string)
+		}
+		p.parentZones[zoneKey(zone)] = candidate
+		return candidate, nil
+	}
+	return zone, nil
+}
+
+// inParentZone runs a batch operation on the records of the zone in its
+// parent zone, see parentZone, and returns the results with names relative
+// to the zone again.
+func (p *Provider) inParentZone(ctx context.Context, zone string, records []libdns.Record, op func(context.Context, string, []libdns.Record) ([]RecordResult, error)) ([]RecordResult, error) {
+	if !p.FindParentZone {
+		return op(ctx, zone, records)
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	parent, err := p.parentZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	if parent == zone {
+		return op(ctx, zone, records)
+	}
+	rebased := make([]libdns.Record, len(records))
+	for i, record := range records {
+		rebased[i] = rebaseRecord(record, zone, parent)
+	}
+	results, err := op(ctx, parent, rebased)
+	for i := range results {
+		results[i].Record = rebaseRecord(results[i].Record, parent, zone)
+	}
+	return results, err
+}
+
+// recordsInZone returns the records of the parent zone that belong to the
+// zone, with names relative to the zone.
+func recordsInZone(records []libdns.Record, parent, zone string) []libdns.Record {
+	suffix := "." + strings.ToLower(strings.TrimSuffix(zone, "."))
+	var inZone []libdns.Record
+	for _, record := range records {
+		name := strings.ToLower(strings.TrimSuffix(libdns.AbsoluteName(record.RR().Name, parent), "."))
+		if "."+name == suffix || strings.HasSuffix(name, suffix) {
+			inZone = append(inZone, rebaseRecord(record, parent, zone))
+		}
+	}
+	return inZone
+}
+
+// rebaseRecord returns the record with its name, relative to the zone from,
+// made relative to the zone to, keeping its Linode ID.
+func rebaseRecord(record libdns.Record, from, to string) libdns.Record {
+	rr := record.RR()
+	rr.Name = libdns.RelativeName(libdns.AbsoluteName(rr.Name, from), to)
+	rebased, err := parseRecord(rr)
+	if err != nil {
+		return rr
+	}
+	if id, ok := recordID(record); ok {
+		rebased = withRecordID(rebased, id)
+	}
+	return rebased
+}
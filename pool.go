@@ -0,0 +1,105 @@
+package linode
+
+import (
+	"context"
+	"sync"
+)
+
+// workerPool runs the record operations of the batch calls of a provider on
+// a fixed number of goroutines, started once and shared by concurrent calls.
+type workerPool struct {
+	tasks chan func()
+}
+
+func newWorkerPool(workers int) *workerPool {
+	pool := &workerPool{tasks: make(chan func())}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range pool.tasks {
+				task()
+			}
+		}()
+	}
+	return pool
+}
+
+// workerPool returns the provider's worker pool, starting it on first use.
+func (p *Provider) workerPool() *workerPool {
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+	if p.pool == nil {
+		p.pool = newWorkerPool(p.Concurrency)
+	}
+	return p.pool
+}
+
+// stopWorkerPool stops the workers of the provider's pool, if it was started.
+func (p *Provider) stopWorkerPool() {
+	p.poolMutex.Lock()
+	defer p.poolMutex.Unlock()
+	if p.pool != nil {
+		close(p.pool.tasks)
+		p.pool = nil
+	}
+}
+
+// forEach calls task for the indexes 0 to n-1 and waits for the calls to
+// return. With a Concurrency above 1 the calls run on the worker pool,
+// otherwise in order. After a call returns an error, no further calls are
+// started and the error is returned.
+//
+// The caller must hold p.mutex. On the worker pool, it is released until the
+// calls returned, so that calls on other zones can use the pool meanwhile;
+// the tasks must not use state guarded by p.mutex, and writes must hold the
+// zone's lock, see lockZone.
+func (p *Provider) forEach(ctx context.Context, n int, task func(i int) error) error {
+	if p.Concurrency <= 1 {
+		for i := 0; i < n; i++ {
+			if err := task(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	pool := p.workerPool()
+	p.mutex.Unlock()
+	defer p.mutex.Lock()
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	failed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return firstErr != nil
+	}
+	fail := func(err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for i := 0; i < n && !failed(); i++ {
+		i := i
+		wg.Add(1)
+		run := func() {
+			defer wg.Done()
+			if failed() {
+				return
+			}
+			if err := task(i); err != nil {
+				fail(err)
+			}
+		}
+		select {
+		case pool.tasks <- run:
+		case <-ctx.Done():
+			wg.Done()
+			fail(ctx.Err())
+		}
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,41 @@
+package linode
+
+import (
+	"net/netip"
+
+	"github.com/libdns/libdns"
+)
+
+// ZonePreset is a named bundle of records that CreateZone adds to new zones,
+// so that they start from a sane baseline.
+type ZonePreset struct {
+	Name    string
+	Records []libdns.Record
+}
+
+// MailPreset returns the "mail" preset: an MX record for mailServer, an SPF
+// policy allowing only the MX hosts to send mail, and a DMARC policy that
+// sends reports to reportAddress without affecting delivery.
+func MailPreset(mailServer, reportAddress string) ZonePreset {
+	return ZonePreset{
+		Name: "mail",
+		Records: []libdns.Record{
+			libdns.MX{Name: "@", Preference: 10, Target: mailServer},
+			libdns.TXT{Name: "@", Text: "v=spf1 mx -all"},
+			libdns.TXT{Name: "_dmarc", Text: "v=DMARC1; p=none; rua=mailto:" + reportAddress},
+		},
+	}
+}
+
+// WebPreset returns the "web" preset: A and AAAA records at the zone apex for
+// the addresses that are valid, and a www CNAME to the apex.
+func WebPreset(zone string, ipv4, ipv6 netip.Addr) ZonePreset {
+	preset := ZonePreset{Name: "web"}
+	for _, ip := range []netip.Addr{ipv4, ipv6} {
+		if ip.IsValid() {
+			preset.Records = append(preset.Records, libdns.Address{Name: "@", IP: ip})
+		}
+	}
+	preset.Records = append(preset.Records, libdns.CNAME{Name: "www", Target: libdns.AbsoluteName("@", zone)})
+	return preset
+}
@@ -0,0 +1,140 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// zoneRenderDelay is how long Linode takes to publish changes to a zone on its
+// nameservers.
+const zoneRenderDelay = 30 * time.Second
+
+// linodeNameservers are Linode's authoritative nameservers.
+var linodeNameservers = []string{"ns1.linode.com", "ns2.linode.com", "ns3.linode.com", "ns4.linode.com", "ns5.linode.com"}
+
+// defaultDomainTTL is the TTL Linode uses when a domain has no TTL of its own.
+const defaultDomainTTL = 24 * time.Hour
+
+// PropagationEstimate returns how long to wait after changing the records with
+// the given name and type until resolvers can be expected to see the change,
+// e.g. before asking an ACME CA to validate a DNS-01 challenge. It is Linode's
+// zone render delay plus the longest TTL for which resolvers may have cached
+// the current records; records without a TTL of their own use the domain's.
+func (p *Provider) PropagationEstimate(ctx context.Context, zone, name, recordType string) (time.Duration, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return 0, err
+	}
+	ctx = withZone(ctx, zone)
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return 0, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	records, err := p.listDomainRecords(ctx, zone, domainID)
+	if err != nil {
+		return 0, err
+	}
+	key := newRRSetKey(zone, libdns.RR{Name: name, Type: recordType})
+	var previousTTL time.Duration
+	usesDomainTTL := false
+	for _, record := range records {
+		if newRRSetKey(zone, record) != key {
+			continue
+		}
+		ttl := record.RR().TTL
+		if ttl == 0 {
+			usesDomainTTL = true
+		}
+		if ttl > previousTTL {
+			previousTTL = ttl
+		}
+	}
+	if usesDomainTTL {
+		domainTTL, err := p.domainTTL(ctx, domainID)
+		if err != nil {
+			return 0, err
+		}
+		if domainTTL > previousTTL {
+			previousTTL = domainTTL
+		}
+	}
+	return zoneRenderDelay + previousTTL, nil
+}
+
+// domainTTL returns the TTL of the records of a domain that have no TTL of
+// their own.
+func (p *Provider) domainTTL(ctx context.Context, domainID int) (time.Duration, error) {
+	domain, err := p.api.GetDomain(ctx, domainID)
+	if err != nil {
+		return 0, fmt.Errorf("could not get domain: %w", err)
+	}
+	if domain.TTLSec == 0 {
+		return defaultDomainTTL, nil
+	}
+	return time.Duration(domain.TTLSec) * time.Second, nil
+}
+
+// WaitOptions configures WaitForRecord.
+type WaitOptions struct {
+	// Nameservers are the nameservers that must serve the record, by
+	// default Linode's ns1.linode.com to ns5.linode.com.
+	Nameservers []string
+	// Interval is the time between queries, 5 seconds by default.
+	Interval time.Duration
+}
+
+// WaitForRecord waits until the nameservers serve the record, e.g. before
+// asking an ACME CA to validate a DNS-01 challenge, since Linode takes about
+// 30 seconds to publish changes. The nameservers are queried directly,
+// unless Resolver is set, which is then asked in place of each of them, e.g.
+// to stub lookups in tests. It returns an error wrapping the context's error
+// if the context ends first.
+func (p *Provider) WaitForRecord(ctx context.Context, zone string, record libdns.Record, opts WaitOptions) error {
+	pending := opts.Nameservers
+	if len(pending) == 0 {
+		pending = linodeNameservers
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	rr := record.RR()
+	name := libdns.AbsoluteName(rr.Name, zone)
+	want := newRecordKey(zone, record)
+	for {
+		var remaining []string
+		for _, server := range pending {
+			// Failed queries are retried like records not served yet.
+			served, _ := p.resolver(server).Lookup(ctx, name, rr.Type)
+			found := false
+			for _, candidate := range served {
+				if newRecordKey(zone, candidate) == want {
+					found = true
+					break
+				}
+			}
+			if !found {
+				remaining = append(remaining, server)
+			}
+		}
+		if len(remaining) == 0 {
+			return nil
+		}
+		pending = remaining
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s %s is not served by %s: %w", name, rr.Type, strings.Join(pending, ", "), ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+// stubResolver serves the records it holds, counting the lookups.
+type stubResolver struct {
+	mutex   sync.Mutex
+	records []libdns.RR
+	lookups int
+}
+
+func (r *stubResolver) Lookup(_ context.Context, name, recordType string) ([]libdns.RR, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.lookups++
+	var found []libdns.RR
+	for _, record := range r.records {
+		if record.Name == name && record.Type == recordType {
+			found = append(found, record)
+		}
+	}
+	return found, nil
+}
+
+func (r *stubResolver) serve(record libdns.RR) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.records = append(r.records, record)
+}
+
+func TestWaitForRecordUsesResolver(t *testing.T) {
+	resolver := new(stubResolver)
+	provider := &linode.Provider{Resolver: resolver}
+	record := libdns.TXT{Name: "_acme-challenge", Text: "token"}
+	opts := linode.WaitOptions{Nameservers: []string{"ns1.example.net"}, Interval: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := provider.WaitForRecord(ctx, testZone, record, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("WaitForRecord = %v, want DeadlineExceeded", err)
+	}
+
+	resolver.serve(libdns.RR{Name: "_acme-challenge.example.com.", Type: "TXT", Data: "token"})
+	if err := provider.WaitForRecord(context.Background(), testZone, record, opts); err != nil {
+		t.Fatalf("WaitForRecord: %v", err)
+	}
+	if resolver.lookups < 2 {
+		t.Fatalf("resolver was asked %d times", resolver.lookups)
+	}
+}
@@ -4,101 +4,344 @@ package linode
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
 	"sync"
+	"time"
 
 	"github.com/libdns/libdns"
 	"github.com/linode/linodego"
 )
 
 // Provider facilitates DNS record manipulation with Linode.
+//
+// A Provider can be decoded from JSON configuration. The API token, URL and
+// version may contain {env.VAR} placeholders, which are replaced with the
+// values of the environment variables when the provider is first used, so
+// that configuration files can be committed without secrets. If they are
+// empty, the LINODE_TOKEN, LINODE_API_URL and LINODE_API_VERSION environment
+// variables are used instead.
+//
+// A Provider is safe for concurrent use by multiple goroutines once it is
+// configured; its fields must not be changed after first use. Calls that
+// read or write records are serialized, except for ForEachRecord callbacks
+// and the record writes of batch calls, which run on the worker pool of
+// Concurrency alongside those of calls on other zones. Writes to the same
+// zone are never interleaved.
 type Provider struct {
 	// APIToken is the Linode Personal Access Token, see https://cloud.linode.com/profile/tokens.
 	APIToken string `json:"api_token,omitempty"`
+	// TokenSource, if set, supplies the API token for every request instead
+	// of APIToken, so that rotated tokens are picked up.
+	TokenSource TokenSource `json:"-"`
 	// APIURL is the Linode API hostname, i.e. "api.linode.com".
 	APIURL string `json:"api_url,omitempty"`
 	// APIVersion is the Linode API version, i.e. "v4".
 	APIVersion string `json:"api_version,omitempty"`
-	client     linodego.Client
-	once       sync.Once
-	mutex      sync.Mutex
+	// UserAgent is appended to the User-Agent header of API requests, e.g.
+	// "billing-certs/1.2", to identify the service making changes in Linode's
+	// logs.
+	UserAgent string `json:"user_agent,omitempty"`
+	// ContinueOnError makes AppendRecords, SetRecords and DeleteRecords process
+	// the remaining records when one of them fails, instead of stopping at the
+	// first error. The records that succeeded are returned along with a
+	// *BatchError describing the failures.
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
+	// Concurrency is the number of records AppendRecords, SetRecords and
+	// DeleteRecords write in parallel. The workers are started once and
+	// shared by the concurrent calls of the provider, so that zones synced at
+	// the same time never make more concurrent writes than this together.
+	// Records are written one at a time by default.
+	Concurrency int `json:"concurrency,omitempty"`
+	// RecordCacheTTL enables caching the records returned by GetRecords for
+	// the given duration. Writes made through the provider invalidate the
+	// cached records of their zone.
+	RecordCacheTTL time.Duration `json:"record_cache_ttl,omitempty"`
+	// StaleWhileRevalidate allows GetRecords to keep serving cached records for
+	// this long after they expired, while they are refreshed in the background.
+	// It only has an effect when RecordCacheTTL is set.
+	StaleWhileRevalidate time.Duration `json:"stale_while_revalidate,omitempty"`
+	// DomainIDCacheTTL caches the IDs of the zones' domains in memory for
+	// this long, so that repeated operations on a zone look it up only once.
+	// Without it, domain IDs are cached only along with records or in the
+	// CacheFile, and then do not expire. A cached ID is dropped when its
+	// domain is not found.
+	DomainIDCacheTTL time.Duration `json:"domain_id_cache_ttl,omitempty"`
+	// ServeStaleOnOutage makes GetRecords return the records it last fetched
+	// for a zone, however old, when the Linode API is unreachable or failing.
+	// Use RecordsStale to find out whether records were served this way.
+	ServeStaleOnOutage bool `json:"serve_stale_on_outage,omitempty"`
+	// HTTPClient, if set, is used to talk to the Linode API, for proxies,
+	// custom timeouts or instrumentation. The connection and TLS settings
+	// below are then ignored; configure them on its Transport instead.
+	HTTPClient *http.Client `json:"-"`
+	// MaxIdleConns limits the idle connections kept open to the Linode API.
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+	// IdleConnTimeout is how long an idle connection is kept open.
+	IdleConnTimeout time.Duration `json:"idle_conn_timeout,omitempty"`
+	// TLSHandshakeTimeout limits the time spent on TLS handshakes.
+	TLSHandshakeTimeout time.Duration `json:"tls_handshake_timeout,omitempty"`
+	// ForceHTTP2 makes a tuned transport negotiate HTTP/2. Setting any of the
+	// transport options above replaces http.DefaultClient with a dedicated
+	// transport, which otherwise speaks HTTP/1.1.
+	ForceHTTP2 bool `json:"force_http2,omitempty"`
+	// CACertFile is the path of a PEM file with additional root CAs to trust,
+	// e.g. those of a TLS-intercepting gateway in front of APIURL.
+	CACertFile string `json:"ca_cert_file,omitempty"`
+	// ClientCertFile and ClientKeyFile are the paths of a PEM client
+	// certificate and key presented to the API for mutual TLS.
+	ClientCertFile string `json:"client_cert_file,omitempty"`
+	ClientKeyFile  string `json:"client_key_file,omitempty"`
+	// RootCAs replaces the system root CAs used to verify the API.
+	// Certificates from CACertFile are added to it.
+	RootCAs *x509.CertPool `json:"-"`
+	// ClientCertificates are presented to the API for mutual TLS, in addition
+	// to the one loaded from ClientCertFile.
+	ClientCertificates []tls.Certificate `json:"-"`
+	// API, if set, is used instead of a Linode API client made from the
+	// settings above, such as a linodetest.Fake in tests. The connection,
+	// retry, rate limit, logging, metrics and API call tracing settings don't
+	// apply to it, and ImportZone and CloneZone are not supported with it.
+	API DomainAPI `json:"-"`
+	// Logger, if set, logs every Linode API call.
+	Logger Logger `json:"-"`
+	// Metrics, if set, receives the duration and outcome of every Linode API
+	// call and the delays of the client-side rate limiter.
+	Metrics Metrics `json:"-"`
+	// Tracer, if set, starts spans around record operations and Linode API
+	// calls for distributed tracing.
+	Tracer Tracer `json:"-"`
+	// WebhookURL receives a POST with a JSON WebhookPayload after records
+	// were changed through the provider.
+	WebhookURL string `json:"webhook_url,omitempty"`
+	// WebhookSecret signs webhook bodies, see WebhookSignatureHeader.
+	WebhookSecret string `json:"webhook_secret,omitempty"`
+	// WebhookActor identifies the application making the changes in webhook payloads.
+	WebhookActor string `json:"webhook_actor,omitempty"`
+	// RateLimit enables a client-side rate limiter allowing at most this many
+	// API requests per RateLimitWindow (one minute by default).
+	RateLimit       int           `json:"rate_limit,omitempty"`
+	RateLimitWindow time.Duration `json:"rate_limit_window,omitempty"`
+	// RateLimitStore holds the rate limiter counters. By default they are kept
+	// in memory; a shared store lets replicas coordinate.
+	RateLimitStore RateLimitStore `json:"-"`
+	// RateLimitKey identifies the counters in the store. It defaults to a hash
+	// of the API token, so that all users of a token share one budget. With a
+	// TokenSource the hash changes when the token is rotated, so set
+	// RateLimitKey to keep one budget across rotations.
+	RateLimitKey string `json:"rate_limit_key,omitempty"`
+	// RateLimitHeadroom is the number of requests per window that requests
+	// made with WithLowPriority leave for others.
+	RateLimitHeadroom int `json:"rate_limit_headroom,omitempty"`
+	// RetryMaxWait is the longest a request throttled by the Linode API
+	// (HTTP 429) waits for the time given by its Retry-After header before it
+	// is retried, 30 seconds by default. If the wait would outlast the
+	// request's context, the request fails at once instead.
+	RetryMaxWait time.Duration `json:"retry_max_wait,omitempty"`
+	// MaxRetries enables retrying requests that failed with a network error
+	// or a server error, up to this many times, and also limits the retries
+	// of throttled requests, which are otherwise retried indefinitely. The
+	// waits between retries grow exponentially with jitter from RetryWait,
+	// 3 seconds by default, up to RetryMaxWait, and end with the request's
+	// context. A create whose response was lost may be retried after it
+	// succeeded; IdempotentAppend makes that harmless.
+	MaxRetries int           `json:"max_retries,omitempty"`
+	RetryWait  time.Duration `json:"retry_wait,omitempty"`
+	// ZoneLocker, if set, is locked around writes to a zone, so that several
+	// instances managing the same zone don't make conflicting changes.
+	ZoneLocker ZoneLocker `json:"-"`
+	// IdempotentAppend makes AppendRecords return the existing record instead of
+	// an error when Linode rejects a create because an identical record
+	// already exists, so that retried calls succeed.
+	IdempotentAppend bool `json:"idempotent_append,omitempty"`
+	// SkipExisting makes AppendRecords leave out records that are already in
+	// the zone with the same name, type, data and TTL, and return the
+	// existing records for them instead of creating duplicates. It costs a
+	// listing of the zone per call.
+	SkipExisting bool `json:"skip_existing,omitempty"`
+	// IdempotentDelete makes DeleteRecords treat records that Linode reports as
+	// not found as deleted, and include them in the returned records.
+	IdempotentDelete bool `json:"idempotent_delete,omitempty"`
+	// DefaultTTL is used for records written with a zero TTL. When it is
+	// zero as well, Linode applies the domain's default TTL.
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+	// ReadOnly rejects all writes with ErrReadOnly.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// StrictNames rejects records whose name repeats the zone, such as
+	// "www.example.com.example.com" in the zone "example.com.", with
+	// ErrInvalidRecord instead of correcting them to "www".
+	StrictNames bool `json:"strict_names,omitempty"`
+	// AllowedNames restricts the records that may be written to those whose
+	// name relative to the zone matches one of these patterns, in the syntax
+	// of path.Match; e.g. "_acme-challenge*" or "*.dyn". The zone apex is
+	// "@". Other writes fail with ErrOutOfScope. All names are allowed if empty.
+	AllowedNames []string `json:"allowed_names,omitempty"`
+	// AllowedTypes restricts the records that may be created, updated or
+	// deleted to these types, e.g. only "TXT" for ACME challenges, and
+	// DeniedTypes excludes types. Both are checked before any API request;
+	// other writes fail with ErrOutOfScope.
+	AllowedTypes []string `json:"allowed_types,omitempty"`
+	DeniedTypes  []string `json:"denied_types,omitempty"`
+	// MaxDeletionsPerCall aborts DeleteRecords, SetRecords and ApplyChanges
+	// calls that would delete more records than this, and MaxDeletionPercent
+	// those that would delete more than this percentage of the zone's
+	// records, with ErrTooManyDeletions. Use WithForce to exceed the limits deliberately.
+	MaxDeletionsPerCall int     `json:"max_deletions_per_call,omitempty"`
+	MaxDeletionPercent  float64 `json:"max_deletion_percent,omitempty"`
+	// Confirm, if set, is asked before SetRecords, DeleteRecords and
+	// ApplyChanges delete records or replace records at the zone apex; if it
+	// returns false, the call fails with ErrNotConfirmed without changing the
+	// zone.
+	Confirm ConfirmFunc `json:"-"`
+	// DomainID is the ID of the Linode domain of the zone DomainZone, for
+	// deployments that manage a single zone; it applies to no other zone.
+	// With it, or ZoneConfig.DomainID, zones are not looked up by name,
+	// which saves a request per operation and works with tokens that cannot
+	// list domains.
+	DomainID   int    `json:"domain_id,omitempty"`
+	DomainZone string `json:"domain_zone,omitempty"`
+	// FindParentZone makes zones that are not Linode domains themselves, such
+	// as "sub.deep.example.com.", resolve to the closest enclosing domain,
+	// such as "example.com.", as ACME clients that pass the full name of a
+	// challenge expect. GetRecords, AppendRecords, SetRecords and
+	// DeleteRecords then work on the records of that domain below the zone,
+	// with names relative to the zone.
+	FindParentZone bool `json:"find_parent_zone,omitempty"`
+	// Zones overrides the settings above for individual zones.
+	Zones map[string]ZoneConfig `json:"zones,omitempty"`
+	// StrictParsing makes reading a zone fail with ErrMalformedRecord when
+	// a record's data cannot be parsed for its type, such as an MX record
+	// without a target. Otherwise such records are returned as libdns.RR,
+	// which carry no Linode record ID.
+	StrictParsing bool `json:"strict_parsing,omitempty"`
+	// OnMalformedRecord, if set, is called for every record of a zone that
+	// is returned as libdns.RR because its data cannot be parsed.
+	OnMalformedRecord func(zone string, record libdns.RR) `json:"-"`
+	// ResolveDefaultTTL makes GetRecords report the domain's TTL for records
+	// that have none of their own, instead of 0. Such records passed back to
+	// SetRecords are written with that TTL as their own.
+	ResolveDefaultTTL bool `json:"resolve_default_ttl,omitempty"`
+	// UseAXFR makes GetRecords read zones with a DNS zone transfer instead of
+	// paging through the API, which takes a single connection for very large
+	// zones. The host's IP address must be in the domain's AXFR IPs. Records
+	// read this way carry no Linode record IDs. If the transfer fails, the
+	// records are read from the API.
+	UseAXFR bool `json:"use_axfr,omitempty"`
+	// AXFRServer is the nameserver zones are transferred from, by default
+	// "ns1.linode.com:53".
+	AXFRServer string `json:"axfr_server,omitempty"`
+	// Resolver is used by the features that look records up over DNS, such
+	// as WaitForRecord. By default they query the nameservers they check
+	// directly, or the system resolver.
+	Resolver Resolver `json:"-"`
+	// CacheFile is the path of a file the domain IDs and, if RecordCacheTTL is
+	// set, the cached records are loaded from on first use and saved to by
+	// Close, so that short-lived processes don't list everything again. The
+	// file is ignored if it was written for another API token.
+	CacheFile string `json:"cache_file,omitempty"`
+
+	client          linodego.Client
+	api             DomainAPI
+	once            sync.Once
+	initErr         error
+	mutex           sync.Mutex
+	recordCache     map[string]*recordCacheEntry
+	domainIDs       map[string]int
+	domainIDsCached map[string]time.Time
+	parentZones     map[string]string
+	zoneLocks       map[string]chan struct{}
+
+	memoryRateLimitStore MemoryRateLimitStore
+	stats                statsCounters
+	poolMutex            sync.Mutex
+	pool                 *workerPool
+	freezeMutex          sync.Mutex
+	frozen               map[string]time.Time
 }
 
 // GetRecords lists all the records in the zone.
-func (p *Provider) GetRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
-	p.mutex.Lock()
+func (p *Provider) GetRecords(ctx context.Context, zone string) (records []libdns.Record, err error) {
+	ctx, end := p.startSpan(ctx, "GetRecords", zone, nil)
+	defer func() { end(err) }()
+	ctx, err = p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
 	defer p.mutex.Unlock()
-	p.init(ctx)
-	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	parent, err := p.parentZone(ctx, zone)
 	if err != nil {
-		return nil, fmt.Errorf("could not find domain ID for zone: %s: %v", zone, err)
+		return nil, err
 	}
-	records, err := p.listDomainRecords(ctx, zone, domainID)
+	if parent != zone {
+		records, err := p.getRecords(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		return recordsInZone(records, parent, zone), nil
+	}
+	return p.getRecords(ctx, zone)
+}
+
+func (p *Provider) getRecords(ctx context.Context, zone string) ([]libdns.Record, error) {
+	ctx = withZone(ctx, zone)
+	if records, ok := p.cachedRecords(zone); ok {
+		return records, nil
+	}
+	records, err := p.readRecords(ctx, zone)
 	if err != nil {
+		if records, ok := p.staleRecords(ctx, zone, err); ok {
+			return records, nil
+		}
 		return nil, err
 	}
+	p.cacheRecords(zone, records)
 	return records, nil
 }
 
 // AppendRecords adds records to the zone. It returns the records that were added.
-func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.init(ctx)
-	domainID, err := p.getDomainIDByZone(ctx, zone)
+func (p *Provider) AppendRecords(ctx context.Context, zone string, records []libdns.Record) (_ []libdns.Record, err error) {
+	ctx, end := p.startSpan(ctx, "AppendRecords", zone, records)
+	defer func() { end(err) }()
+	ctx, err = p.lock(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not find domain ID for zone: %s: %v", zone, err)
-	}
-	addedRecords := make([]libdns.Record, 0, len(records))
-	for _, record := range records {
-		addedRecord, err := p.createDomainRecord(ctx, zone, domainID, record)
-		if err != nil {
-			return nil, err
-		}
-		addedRecords = append(addedRecords, addedRecord)
+		return nil, err
 	}
-	return addedRecords, nil
+	defer p.mutex.Unlock()
+	results, err := p.inParentZone(ctx, zone, records, p.appendRecords)
+	return p.resultRecords(results, err, OutcomeCreated, OutcomeNoOp)
 }
 
 // SetRecords sets the records in the zone, either by updating existing records or creating new ones.
+// For every name and type in the input, the zone ends up with exactly the given records: the zone is
+// listed once, and only the records that differ are created, updated or deleted.
 // It returns the updated records.
-func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.init(ctx)
-	domainID, err := p.getDomainIDByZone(ctx, zone)
+func (p *Provider) SetRecords(ctx context.Context, zone string, records []libdns.Record) (_ []libdns.Record, err error) {
+	ctx, end := p.startSpan(ctx, "SetRecords", zone, records)
+	defer func() { end(err) }()
+	ctx, err = p.lock(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not find domain ID for zone: %s: %v", zone, err)
-	}
-	updatedRecords := make([]libdns.Record, 0, len(records))
-	for _, record := range records {
-		updatedRecord, err := p.createOrUpdateDomainRecord(ctx, zone, domainID, record)
-		if err != nil {
-			return nil, err
-		}
-		updatedRecords = append(updatedRecords, updatedRecord)
+		return nil, err
 	}
-	return updatedRecords, nil
+	defer p.mutex.Unlock()
+	results, err := p.inParentZone(ctx, zone, records, p.setRecords)
+	return p.resultRecords(results, err, OutcomeCreated, OutcomeUpdated, OutcomeNoOp)
 }
 
 // DeleteRecords deletes the records from the zone. It returns the records that were deleted.
-func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) ([]libdns.Record, error) {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	p.init(ctx)
-	domainID, err := p.getDomainIDByZone(ctx, zone)
+func (p *Provider) DeleteRecords(ctx context.Context, zone string, records []libdns.Record) (_ []libdns.Record, err error) {
+	ctx, end := p.startSpan(ctx, "DeleteRecords", zone, records)
+	defer func() { end(err) }()
+	ctx, err = p.lock(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("could not find domain ID for zone: %s: %v", zone, err)
-	}
-	deletedRecords := make([]libdns.Record, 0, len(records))
-	for _, record := range records {
-		err := p.deleteDomainRecord(ctx, domainID, record)
-		if err != nil {
-			return nil, err
-		}
-		deletedRecords = append(deletedRecords, record)
+		return nil, err
 	}
-	return deletedRecords, nil
+	defer p.mutex.Unlock()
+	results, err := p.inParentZone(ctx, zone, records, p.deleteRecords)
+	return p.resultRecords(results, err, OutcomeDeleted)
 }
 
 // Interface guards
@@ -107,4 +350,6 @@ var (
 	_ libdns.RecordAppender = (*Provider)(nil)
 	_ libdns.RecordSetter   = (*Provider)(nil)
 	_ libdns.RecordDeleter  = (*Provider)(nil)
+	_ libdns.ZoneLister     = (*Provider)(nil)
+	_ libdns.Record         = PTR{}
 )
@@ -0,0 +1,61 @@
+package linode_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+)
+
+const testZone = "example.com."
+
+// newTestProvider returns a provider backed by a fake with the domain of
+// testZone, and the fake and domain ID.
+func newTestProvider(t *testing.T) (*linode.Provider, *linodetest.Fake, int) {
+	t.Helper()
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("example.com")
+	return &linode.Provider{API: fake}, fake, domainID
+}
+
+// mustAppend adds the records to testZone, failing the test on errors.
+func mustAppend(t *testing.T, provider *linode.Provider, records ...libdns.Record) []libdns.Record {
+	t.Helper()
+	added, err := provider.AppendRecords(context.Background(), testZone, records)
+	if err != nil {
+		t.Fatalf("AppendRecords: %v", err)
+	}
+	return added
+}
+
+// mustGet returns the records of testZone, failing the test on errors.
+func mustSet(t *testing.T, provider *linode.Provider, records ...libdns.Record) []libdns.Record {
+	t.Helper()
+	set, err := provider.SetRecords(context.Background(), testZone, records)
+	if err != nil {
+		t.Fatalf("SetRecords: %v", err)
+	}
+	return set
+}
+
+func mustGet(t *testing.T, provider *linode.Provider) []libdns.Record {
+	t.Helper()
+	records, err := provider.GetRecords(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	return records
+}
+
+// mustParse returns the record with the name, type and data, failing the
+// test if they don't parse.
+func mustParse(t *testing.T, name, recordType, data string) libdns.Record {
+	t.Helper()
+	record, err := libdns.RR{Name: name, Type: recordType, Data: data}.Parse()
+	if err != nil {
+		t.Fatalf("parse %s %s %q: %v", name, recordType, data, err)
+	}
+	return record
+}
@@ -0,0 +1,39 @@
+package linode
+
+import (
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// PTR is a pointer record, which libdns has no type for. Records of Linode
+// reverse zones are returned as PTR, so that they carry their Linode ID like
+// the records of other types. A libdns.RR with type "PTR" can be written as
+// well.
+type PTR struct {
+	Name string
+	TTL  time.Duration
+	// Target is the name the record points to.
+	Target string
+	// ProviderData holds the Linode ID of records read from Linode.
+	ProviderData any
+}
+
+// RR returns the record as a generic resource record.
+func (r PTR) RR() libdns.RR {
+	return libdns.RR{
+		Name: r.Name,
+		TTL:  r.TTL,
+		Type: "PTR",
+		Data: r.Target,
+	}
+}
+
+// parseRecord is libdns.RR.Parse, but returns PTR for pointer records.
+func parseRecord(rr libdns.RR) (libdns.Record, error) {
+	if strings.EqualFold(rr.Type, "PTR") {
+		return PTR{Name: rr.Name, TTL: rr.TTL, Target: rr.Data}, nil
+	}
+	return rr.Parse()
+}
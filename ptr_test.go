@@ -0,0 +1,66 @@
+package linode_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+)
+
+func TestPTRRoundTrip(t *testing.T) {
+	const zone = "2.0.192.in-addr.arpa."
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("2.0.192.in-addr.arpa")
+	provider := &linode.Provider{API: fake}
+
+	tests := []struct {
+		name   string
+		record libdns.Record
+	}{
+		{"typed", linode.PTR{Name: "10", TTL: time.Hour, Target: "www.example.com."}},
+		{"generic", libdns.RR{Name: "11", TTL: time.Hour, Type: "PTR", Data: "mail.example.com."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			added, err := provider.AppendRecords(context.Background(), zone, []libdns.Record{test.record})
+			if err != nil {
+				t.Fatalf("AppendRecords: %v", err)
+			}
+			if _, ok := added[0].(linode.PTR); !ok {
+				t.Fatalf("AppendRecords returned a %T, want PTR", added[0])
+			}
+
+			records, err := provider.GetRecords(context.Background(), zone)
+			if err != nil {
+				t.Fatalf("GetRecords: %v", err)
+			}
+			var found linode.PTR
+			for _, record := range records {
+				if ptr, ok := record.(linode.PTR); ok && ptr.Name == test.record.RR().Name {
+					found = ptr
+				}
+			}
+			if found.RR() != test.record.RR() {
+				t.Fatalf("read %+v, want %+v", found.RR(), test.record.RR())
+			}
+			if found.ProviderData == nil {
+				t.Fatal("the PTR record has no Linode ID")
+			}
+
+			updated := found
+			updated.Target = "other.example.com."
+			if _, err := provider.SetRecords(context.Background(), zone, []libdns.Record{updated}); err != nil {
+				t.Fatalf("SetRecords: %v", err)
+			}
+			if _, err := provider.DeleteRecords(context.Background(), zone, []libdns.Record{updated}); err != nil {
+				t.Fatalf("DeleteRecords: %v", err)
+			}
+		})
+	}
+	if records := fake.Records(domainID); len(records) != 0 {
+		t.Fatalf("zone has %d records left, want none", len(records))
+	}
+}
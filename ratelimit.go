@@ -0,0 +1,282 @@
+package linode
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/linode/linodego"
+)
+
+// RateLimitStore holds the request counters of the client-side rate limiter.
+// Replicas using the same Linode token can share a store, for example one
+// backed by Redis INCR and EXPIRE, to collectively stay under the account's
+// rate limit.
+type RateLimitStore interface {
+	// Increment adds one to the counter of key for the window starting at
+	// windowStart and returns the new count. Counters are only needed until
+	// the window ends, ttl after its start.
+	Increment(ctx context.Context, key string, windowStart time.Time, ttl time.Duration) (int64, error)
+}
+
+// MemoryRateLimitStore is a RateLimitStore for a single process.
+type MemoryRateLimitStore struct {
+	mutex    sync.Mutex
+	counters map[string]memoryRateLimitCounter
+}
+
+type memoryRateLimitCounter struct {
+	count   int64
+	expires time.Time
+}
+
+// Increment implements RateLimitStore.
+func (s *MemoryRateLimitStore) Increment(ctx context.Context, key string, windowStart time.Time, ttl time.Duration) (int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	now := time.Now()
+	if s.counters == nil {
+		s.counters = make(map[string]memoryRateLimitCounter)
+	}
+	for k, counter := range s.counters {
+		if now.After(counter.expires) {
+			delete(s.counters, k)
+		}
+	}
+	k := key + "/" + windowStart.UTC().Format(time.RFC3339Nano)
+	counter := s.counters[k]
+	counter.count++
+	counter.expires = windowStart.Add(ttl)
+	s.counters[k] = counter
+	return counter.count, nil
+}
+
+// Reservation is API request budget of the provider-wide rate limiter set
+// aside with Reserve. Requests made with a context from WithReservation use
+// it before waiting for the rate limiter. Reserved budget is counted in the
+// limiter's window it was taken from, and expires with that window.
+type Reservation struct {
+	mutex sync.Mutex
+	// expires holds the end of the window of every reserved request, or the
+	// zero time for requests reserved without a rate limit.
+	expires []time.Time
+}
+
+// Remaining returns the number of reserved requests not used yet that have
+// not expired.
+func (r *Reservation) Remaining() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.dropExpired()
+	return len(r.expires)
+}
+
+func (r *Reservation) take() bool {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.dropExpired()
+	if len(r.expires) == 0 {
+		return false
+	}
+	r.expires = r.expires[1:]
+	return true
+}
+
+// dropExpired removes the reserved requests whose window has ended. The
+// caller must hold r.mutex.
+func (r *Reservation) dropExpired() {
+	now := time.Now()
+	for len(r.expires) > 0 && !r.expires[0].IsZero() && !now.Before(r.expires[0]) {
+		r.expires = r.expires[1:]
+	}
+}
+
+// Reserve sets aside budget for n API requests of the provider-wide rate
+// limiter, waiting until it is available, so that critical work such as
+// certificate renewals can't be starved by background jobs. Without a
+// RateLimit, the reservation never expires.
+func (p *Provider) Reserve(ctx context.Context, n int) (*Reservation, error) {
+	key, err := p.rateLimitKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &Reservation{expires: make([]time.Time, 0, n)}
+	for i := 0; i < n; i++ {
+		windowEnd, err := p.waitRateLimitWindow(ctx, key, p.RateLimit, p.RateLimitWindow)
+		if err != nil {
+			return nil, err
+		}
+		r.expires = append(r.expires, windowEnd)
+	}
+	return r, nil
+}
+
+type reservationContextKey struct{}
+
+// WithReservation returns a context whose API requests use the reservation.
+func WithReservation(ctx context.Context, r *Reservation) context.Context {
+	return context.WithValue(ctx, reservationContextKey{}, r)
+}
+
+type lowPriorityContextKey struct{}
+
+// WithLowPriority returns a context for background work, such as backups and
+// drift scans, whose API requests yield to others by leaving the last
+// RateLimitHeadroom requests of every window unused.
+func WithLowPriority(ctx context.Context) context.Context {
+	return context.WithValue(ctx, lowPriorityContextKey{}, true)
+}
+
+// lock locks the provider for a call made with the context. A call with a
+// context from WithLowPriority first waits for the rate limiter before it
+// takes the lock, so that it doesn't hold up other calls while it is
+// throttled, and its first API request uses the budget it waited for.
+func (p *Provider) lock(ctx context.Context) (context.Context, error) {
+	_, reserved := ctx.Value(reservationContextKey{}).(*Reservation)
+	if isLowPriority(ctx) && p.RateLimit > 0 && !reserved {
+		key, err := p.rateLimitKey(ctx)
+		if err != nil {
+			return ctx, err
+		}
+		windowEnd, err := p.waitRateLimitWindow(ctx, key, p.lowPriorityRateLimit(), p.RateLimitWindow)
+		if err != nil {
+			return ctx, err
+		}
+		ctx = WithReservation(ctx, &Reservation{expires: []time.Time{windowEnd}})
+	}
+	p.mutex.Lock()
+	return ctx, nil
+}
+
+func isLowPriority(ctx context.Context) bool {
+	lowPriority, _ := ctx.Value(lowPriorityContextKey{}).(bool)
+	return lowPriority
+}
+
+// lowPriorityRateLimit returns the provider-wide rate limit of requests made
+// with WithLowPriority.
+func (p *Provider) lowPriorityRateLimit() int {
+	if limit := p.RateLimit - p.RateLimitHeadroom; limit > 1 {
+		return limit
+	}
+	return 1
+}
+
+// waitRateLimit blocks until the rate limiters allow another API request.
+func (p *Provider) waitRateLimit(ctx context.Context) error {
+	limit := p.RateLimit
+	if r, ok := ctx.Value(reservationContextKey{}).(*Reservation); ok && r.take() {
+		limit = 0
+	} else if isLowPriority(ctx) && limit > 0 {
+		limit = p.lowPriorityRateLimit()
+	}
+	var config ZoneConfig
+	zone, ok := zoneFromContext(ctx)
+	if ok {
+		config = p.zoneConfig(zone)
+	}
+	if limit <= 0 && config.RateLimit <= 0 {
+		return nil
+	}
+	key, err := p.rateLimitKey(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := p.waitRateLimitWindow(ctx, key, limit, p.RateLimitWindow); err != nil {
+		return err
+	}
+	_, err = p.waitRateLimitWindow(ctx, key+"/"+zoneKey(zone), config.RateLimit, config.RateLimitWindow)
+	return err
+}
+
+// waitRateLimitWindow blocks until the counter of key allows another request
+// in the current window, and returns the end of the window the request was
+// counted in. Without a limit, it returns at once with a zero time.
+func (p *Provider) waitRateLimitWindow(ctx context.Context, key string, limit int, window time.Duration) (time.Time, error) {
+	if limit <= 0 {
+		return time.Time{}, nil
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	store := p.RateLimitStore
+	if store == nil {
+		store = &p.memoryRateLimitStore
+	}
+	for {
+		windowStart := time.Now().Truncate(window)
+		count, err := store.Increment(ctx, key, windowStart, window)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("rate limiter: %v", err)
+		}
+		if count <= int64(limit) {
+			return windowStart.Add(window), nil
+		}
+		wait := time.Until(windowStart.Add(window))
+		p.stats.update(func(s *Stats) {
+			s.RateLimitWaits++
+			s.RateLimitWaitTime += wait
+		})
+		p.measureRateLimitWait(ctx, wait)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return time.Time{}, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// rateLimitKey returns the key of the rate limiter counters, which is shared
+// by all providers using the same token unless RateLimitKey is set.
+func (p *Provider) rateLimitKey(ctx context.Context) (string, error) {
+	if p.RateLimitKey != "" {
+		return p.RateLimitKey, nil
+	}
+	fingerprint, err := p.tokenFingerprint(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "linode-dns/" + fingerprint, nil
+}
+
+// tokenFingerprint identifies the API token without revealing it. With a
+// TokenSource, it is the fingerprint of the token the source currently
+// returns.
+func (p *Provider) tokenFingerprint(ctx context.Context) (string, error) {
+	token := p.apiToken()
+	if p.TokenSource != nil {
+		var err error
+		if token, err = p.TokenSource.Token(ctx); err != nil {
+			return "", err
+		}
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// retryAfter returns how long to wait before retrying a request throttled by
+// the Linode API, from the Retry-After header of the response. If the wait
+// would outlast the request's context, the request fails with the throttling
+// error instead of waiting in vain.
+func retryAfter(_ *resty.Client, resp *resty.Response) (time.Duration, error) {
+	seconds, err := strconv.Atoi(resp.Header().Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0, nil
+	}
+	wait := time.Duration(seconds) * time.Second
+	if deadline, ok := resp.Request.Context().Deadline(); ok && time.Until(deadline) < wait {
+		return 0, &linodego.Error{
+			Code:     resp.StatusCode(),
+			Message:  fmt.Sprintf("retry after %s exceeds the context deadline", wait),
+			Response: resp.RawResponse,
+		}
+	}
+	return wait, nil
+}
@@ -0,0 +1,133 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/linode"
+)
+
+// newRecordsServer starts an API server answering every request with an
+// empty page of records.
+func newRecordsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data": [], "page": 1, "pages": 1, "results": 0}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestReservationExpiresWithWindow(t *testing.T) {
+	provider := &linode.Provider{APIToken: "token", RateLimit: 5, RateLimitWindow: 50 * time.Millisecond}
+	reservation, err := provider.Reserve(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if n := reservation.Remaining(); n != 2 {
+		t.Fatalf("Remaining = %d, want 2", n)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if n := reservation.Remaining(); n != 0 {
+		t.Fatalf("Remaining after the window = %d, want 0", n)
+	}
+}
+
+func TestReservationWithoutRateLimitDoesNotExpire(t *testing.T) {
+	provider := &linode.Provider{APIToken: "token"}
+	reservation, err := provider.Reserve(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if n := reservation.Remaining(); n != 1 {
+		t.Fatalf("Remaining = %d, want 1", n)
+	}
+}
+
+func TestLowPriorityWaitDoesNotBlockOthers(t *testing.T) {
+	server := newRecordsServer(t)
+	provider := &linode.Provider{
+		APIToken:          "token",
+		APIURL:            server.URL,
+		APIVersion:        "v4",
+		DomainID:          1,
+		DomainZone:        testZone,
+		RateLimit:         3,
+		RateLimitHeadroom: 2,
+		RateLimitWindow:   time.Hour,
+	}
+	low := linode.WithLowPriority(context.Background())
+	if _, err := provider.GetRecords(low, testZone); err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+
+	// The low priority budget is used up, so this call waits until its
+	// context ends.
+	throttled, cancel := context.WithTimeout(low, 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := provider.GetRecords(throttled, testZone)
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	start := time.Now()
+	if _, err := provider.GetRecords(context.Background(), testZone); err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetRecords waited %s for the throttled low priority call", elapsed)
+	}
+	if err := <-done; err == nil {
+		t.Error("the throttled low priority call succeeded")
+	}
+}
+
+// keyStore is a RateLimitStore that records the keys it counts.
+type keyStore struct {
+	linode.MemoryRateLimitStore
+	mutex sync.Mutex
+	keys  []string
+}
+
+func (s *keyStore) Increment(ctx context.Context, key string, windowStart time.Time, ttl time.Duration) (int64, error) {
+	s.mutex.Lock()
+	s.keys = append(s.keys, key)
+	s.mutex.Unlock()
+	return s.MemoryRateLimitStore.Increment(ctx, key, windowStart, ttl)
+}
+
+func TestRateLimitKeyOfTokenSource(t *testing.T) {
+	store := new(keyStore)
+	for _, token := range []string{"first", "second", ""} {
+		token := token
+		provider := &linode.Provider{
+			TokenSource:    linode.TokenSourceFunc(func(context.Context) (string, error) { return token, nil }),
+			RateLimit:      10,
+			RateLimitStore: store,
+		}
+		if _, err := provider.Reserve(context.Background(), 1); err != nil {
+			t.Fatalf("Reserve: %v", err)
+		}
+	}
+	if store.keys[0] == store.keys[1] || store.keys[0] == store.keys[2] || store.keys[1] == store.keys[2] {
+		t.Errorf("providers with different tokens share rate limit keys: %q", store.keys)
+	}
+}
+
+func TestRateLimitKeyTokenSourceError(t *testing.T) {
+	provider := &linode.Provider{
+		TokenSource: linode.TokenSourceFunc(func(context.Context) (string, error) { return "", errors.New("vault sealed") }),
+		RateLimit:   10,
+	}
+	if _, err := provider.Reserve(context.Background(), 1); err == nil {
+		t.Error("Reserve succeeded without a token")
+	}
+}
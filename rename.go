@@ -0,0 +1,141 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// RenameRecords moves the records named oldName to newName, keeping their
+// types, TTLs and data. If types are given, only records of those types are
+// moved. The deletion limits and Confirm are checked for the old records
+// before anything is written. The new records are created before the old ones
+// are deleted; if any of them cannot be created or any old record cannot be
+// deleted, the changes already made are undone, so that the zone is left
+// unchanged unless undoing them fails too. It returns the new records.
+func (p *Provider) RenameRecords(ctx context.Context, zone, oldName, newName string, types ...string) ([]libdns.Record, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	names := []libdns.Record{libdns.RR{Name: oldName}, libdns.RR{Name: newName}}
+	results, err := p.inParentZone(ctx, zone, names, func(ctx context.Context, zone string, names []libdns.Record) ([]RecordResult, error) {
+		created, err := p.renameRecords(ctx, zone, names[0].RR().Name, names[1].RR().Name, types)
+		return plannedResults(created, OutcomeCreated), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return recordsWithOutcome(results, OutcomeCreated), nil
+}
+
+// renameRecords renames the records of the zone, see RenameRecords.
+// The caller must hold p.mutex.
+func (p *Provider) renameRecords(ctx context.Context, zone, oldName, newName string, types []string) ([]libdns.Record, error) {
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	records, err := p.fetchRecords(withZone(ctx, zone), zone)
+	if err != nil {
+		return nil, err
+	}
+	oldName = normalizeName(zone, oldName)
+	var old, renamed []libdns.Record
+	for _, record := range records {
+		rr := record.RR()
+		if _, ok := recordID(record); !ok || normalizeName(zone, rr.Name) != oldName || !hasType(types, rr.Type) {
+			continue
+		}
+		rr.Name = newName
+		newRecord, err := parseRecord(rr)
+		if err != nil {
+			newRecord = rr
+		}
+		old = append(old, record)
+		renamed = append(renamed, newRecord)
+	}
+	if len(old) == 0 {
+		return nil, fmt.Errorf("no records to rename at %s in zone %s", oldName, zone)
+	}
+	if err := p.checkDeletions(ctx, len(old), len(records)); err != nil {
+		return nil, err
+	}
+	if !isDryRun(ctx) {
+		if err := p.confirm(ctx, DestructiveChanges{Zone: zone, Operation: "rename", Deletes: old}); err != nil {
+			return nil, err
+		}
+	}
+
+	// The writes below were checked above, and undoing them must not be
+	// refused.
+	ctx = withConfirmed(WithForce(ctx))
+	results, err := p.appendRecords(ctx, zone, renamed)
+	created := recordsWithOutcome(results, OutcomeCreated)
+	if err != nil {
+		if rollbackErr := p.undoRename(ctx, zone, created, nil); rollbackErr != nil {
+			return nil, fmt.Errorf("could not create renamed records: %v; %v", err, rollbackErr)
+		}
+		return nil, fmt.Errorf("could not create renamed records: %w", err)
+	}
+	results, err = p.deleteRecords(ctx, zone, old)
+	if err != nil {
+		deleted := recordsWithOutcome(results, OutcomeDeleted)
+		if rollbackErr := p.undoRename(ctx, zone, created, deleted); rollbackErr != nil {
+			return nil, fmt.Errorf("could not delete records at %s after renaming: %v; %v", oldName, err, rollbackErr)
+		}
+		return nil, fmt.Errorf("could not delete records at %s after renaming: %w", oldName, err)
+	}
+	return created, nil
+}
+
+// undoRename undoes a failed rename by creating the deleted old records again
+// and then deleting the created new ones.
+func (p *Provider) undoRename(ctx context.Context, zone string, created, deleted []libdns.Record) error {
+	if len(deleted) > 0 {
+		restored := make([]libdns.Record, len(deleted))
+		for i, record := range deleted {
+			rr := record.RR()
+			parsed, err := parseRecord(rr)
+			if err != nil {
+				parsed = rr
+			}
+			restored[i] = parsed
+		}
+		if _, err := p.appendRecords(ctx, zone, restored); err != nil {
+			return fmt.Errorf("could not restore the deleted records: %v", err)
+		}
+	}
+	if len(created) > 0 {
+		if _, err := p.deleteRecords(ctx, zone, created); err != nil {
+			return fmt.Errorf("could not delete the ones created: %v", err)
+		}
+	}
+	return nil
+}
+
+// recordsWithOutcome returns the records of the results with the outcome.
+func recordsWithOutcome(results []RecordResult, outcome Outcome) []libdns.Record {
+	var records []libdns.Record
+	for _, result := range results {
+		if result.Outcome == outcome {
+			records = append(records, result.Record)
+		}
+	}
+	return records
+}
+
+// hasType reports whether types is empty or contains recordType.
+func hasType(types []string, recordType string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	for _, t := range types {
+		if strings.EqualFold(t, recordType) {
+			return true
+		}
+	}
+	return false
+}
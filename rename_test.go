@@ -0,0 +1,133 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// failingDeleteAPI fails the failAt-th record deletion made through it.
+type failingDeleteAPI struct {
+	linode.DomainAPI
+	failAt  int
+	deletes int
+}
+
+func (api *failingDeleteAPI) DeleteDomainRecord(ctx context.Context, domainID, recordID int) error {
+	api.deletes++
+	if api.deletes == api.failAt {
+		return &linodego.Error{Code: 500, Message: "deletion failed"}
+	}
+	return api.DomainAPI.DeleteDomainRecord(ctx, domainID, recordID)
+}
+
+// zoneContents returns the name, type and data of the records of testZone.
+func zoneContents(t *testing.T, provider *linode.Provider) []string {
+	t.Helper()
+	var contents []string
+	for _, record := range mustGet(t, provider) {
+		rr := record.RR()
+		contents = append(contents, fmt.Sprintf("%s %s %s %s", rr.Name, rr.TTL, rr.Type, rr.Data))
+	}
+	sort.Strings(contents)
+	return contents
+}
+
+func TestRenameRecords(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	mustAppend(t, provider,
+		mustParse(t, "old", "A", "192.0.2.1"),
+		libdns.TXT{Name: "old", TTL: time.Hour, Text: "hello"},
+	)
+	renamed, err := provider.RenameRecords(context.Background(), testZone, "old", "new")
+	if err != nil {
+		t.Fatalf("RenameRecords: %v", err)
+	}
+	if len(renamed) != 2 {
+		t.Fatalf("renamed %d records, want 2", len(renamed))
+	}
+	want := []string{"new 0s A 192.0.2.1", "new 1h0m0s TXT hello"}
+	if got := zoneContents(t, provider); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("zone = %q, want %q", got, want)
+	}
+}
+
+func TestRenameRecordsChecksBeforeWriting(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*linode.Provider)
+		err       error
+	}{
+		{"limits", func(p *linode.Provider) { p.MaxDeletionsPerCall = 1 }, linode.ErrTooManyDeletions},
+		{"confirm", func(p *linode.Provider) {
+			p.Confirm = func(context.Context, linode.DestructiveChanges) bool { return false }
+		}, linode.ErrNotConfirmed},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			provider, fake, domainID := newTestProvider(t)
+			mustAppend(t, provider, mustParse(t, "old", "A", "192.0.2.1"), mustParse(t, "old", "TXT", "hello"))
+			test.configure(provider)
+			if _, err := provider.RenameRecords(context.Background(), testZone, "old", "new"); !errors.Is(err, test.err) {
+				t.Fatalf("RenameRecords error = %v, want %v", err, test.err)
+			}
+			for _, record := range fake.Records(domainID) {
+				if record.Name != "old" {
+					t.Errorf("RenameRecords created %s %s", record.Name, record.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestRenameRecordsRollsBackFailedDeletion(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	// The deletion of the second old record fails.
+	api := &failingDeleteAPI{DomainAPI: fake, failAt: 2}
+	confirms := 0
+	provider := &linode.Provider{
+		API: api,
+		Confirm: func(context.Context, linode.DestructiveChanges) bool {
+			confirms++
+			return true
+		},
+	}
+	mustAppend(t, provider, mustParse(t, "old", "A", "192.0.2.1"), mustParse(t, "old", "TXT", "hello"))
+	before := zoneContents(t, provider)
+
+	if _, err := provider.RenameRecords(context.Background(), testZone, "old", "new"); err == nil {
+		t.Fatal("RenameRecords succeeded, want the deletion error")
+	}
+	if confirms != 1 {
+		t.Errorf("Confirm was called %d times, want once", confirms)
+	}
+	if after := zoneContents(t, provider); fmt.Sprint(after) != fmt.Sprint(before) {
+		t.Errorf("zone after the failed rename = %q, want %q", after, before)
+	}
+}
+
+func TestRenameRecordsInParentZone(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	provider.FindParentZone = true
+	mustAppend(t, provider, libdns.TXT{Name: "old.sub", TTL: time.Hour, Text: "hello"})
+	renamed, err := provider.RenameRecords(context.Background(), "sub."+testZone, "old", "new")
+	if err != nil {
+		t.Fatalf("RenameRecords: %v", err)
+	}
+	if len(renamed) != 1 || renamed[0].RR().Name != "new" {
+		t.Errorf("renamed = %v, want new relative to sub.%s", renamed, testZone)
+	}
+	want := []string{"new.sub 1h0m0s TXT hello"}
+	if got := zoneContents(t, provider); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("zone = %q, want %q", got, want)
+	}
+}
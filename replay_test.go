@@ -0,0 +1,145 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/netip"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// replay starts a Replayer with the interactions of the fixture in
+// testdata/replay, and checks when the test ends that they were all used.
+func replay(t *testing.T, fixture string) *linodetest.Replayer {
+	t.Helper()
+	interactions, err := linodetest.LoadInteractions(filepath.Join("testdata", "replay", fixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayer := linodetest.NewReplayer(interactions)
+	t.Cleanup(func() {
+		replayer.Close()
+		if err := replayer.Err(); err != nil {
+			t.Error(err)
+		}
+		if n := replayer.Remaining(); n > 0 {
+			t.Errorf("%d interactions of %s were not replayed", n, fixture)
+		}
+	})
+	return replayer
+}
+
+func TestReplayGetRecords(t *testing.T) {
+	provider := replay(t, "get_records.json").Provider()
+	records, err := provider.GetRecords(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	want := []libdns.Record{
+		libdns.Address{Name: "www", TTL: 5 * time.Minute, IP: netip.MustParseAddr("192.0.2.10")},
+		libdns.MX{Name: "", Preference: 10, Target: "mail.example.com"},
+		libdns.SRV{Service: "sip", Transport: "tcp", Name: "", TTL: time.Hour, Priority: 10, Weight: 60, Port: 5060, Target: "sip.example.com"},
+		libdns.TXT{Name: "", Text: "v=spf1 include:_spf.example.com ~all"},
+		libdns.TXT{Name: "dkim._domainkey", Text: "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"},
+		libdns.CAA{Name: "", Tag: "issue", Value: "letsencrypt.org"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d", len(records), len(want))
+	}
+	for i, record := range records {
+		if reflect.TypeOf(record) != reflect.TypeOf(want[i]) {
+			t.Errorf("record %d is a %T, want %T", i, record, want[i])
+			continue
+		}
+		if got := record.RR(); got != want[i].RR() {
+			t.Errorf("record %d = %+v, want %+v", i, got, want[i].RR())
+		}
+	}
+}
+
+func TestReplayUnauthorized(t *testing.T) {
+	provider := replay(t, "unauthorized.json").Provider()
+	_, err := provider.GetRecords(context.Background(), testZone)
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusUnauthorized {
+		t.Fatalf("GetRecords error = %v, want a 401 linodego.Error", err)
+	}
+}
+
+func TestReplayThrottled(t *testing.T) {
+	provider := replay(t, "throttled.json").Provider()
+	provider.RetryWait = 10 * time.Millisecond
+	records, err := provider.GetRecords(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+	if retries := provider.Stats().Retries; retries != 1 {
+		t.Errorf("Stats().Retries = %d, want 1", retries)
+	}
+}
+
+func TestReplayServerErrorRetried(t *testing.T) {
+	provider := replay(t, "server_error.json").Provider()
+	provider.MaxRetries = 1
+	provider.RetryWait = 10 * time.Millisecond
+	provider.RetryMaxWait = 10 * time.Millisecond
+	records, err := provider.GetRecords(context.Background(), testZone)
+	if err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("got %d records, want 1", len(records))
+	}
+}
+
+func TestReplayDomainDeleted(t *testing.T) {
+	provider := replay(t, "domain_deleted.json").Provider()
+	if _, err := provider.GetRecords(context.Background(), testZone); !errors.Is(err, linode.ErrZoneNotFound) {
+		t.Fatalf("GetRecords error = %v, want ErrZoneNotFound", err)
+	}
+}
+
+// operationsMetrics records the operations of the API calls it receives.
+type operationsMetrics struct {
+	mutex      sync.Mutex
+	operations []string
+}
+
+func (m *operationsMetrics) APICall(call linode.APICall) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.operations = append(m.operations, call.Operation)
+}
+
+func (m *operationsMetrics) RateLimitWait(string, time.Duration) {}
+
+func TestReplayAPIOperations(t *testing.T) {
+	provider := replay(t, "get_records.json").Provider()
+	metrics := new(operationsMetrics)
+	provider.Metrics = metrics
+	if _, err := provider.GetRecords(context.Background(), testZone); err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	want := []string{"GET domains", "GET domains/{id}/records", "GET domains/{id}/records"}
+	if !reflect.DeepEqual(metrics.operations, want) {
+		t.Errorf("Metrics operations = %q, want %q", metrics.operations, want)
+	}
+	for operation := range provider.Stats().APICalls {
+		if strings.HasPrefix(operation, "GET v4") {
+			t.Errorf("Stats counts %q with the API version", operation)
+		}
+	}
+}
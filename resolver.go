@@ -0,0 +1,228 @@
+package linode
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/miekg/dns"
+)
+
+// Resolver looks up records over DNS, for the features that verify what
+// resolvers see rather than what the Linode API returns. Implementations can
+// stub lookups in tests or force specific resolvers.
+type Resolver interface {
+	// Lookup returns the records of the given type at the fully-qualified
+	// name. The names of the returned records are fully qualified, and their
+	// data is in zone file format. A name without records of the type is not
+	// an error.
+	Lookup(ctx context.Context, name, recordType string) ([]libdns.RR, error)
+}
+
+// NetResolver is a Resolver using a net.Resolver, by default the system's.
+// It supports the A, AAAA, CNAME, MX, NS, SRV and TXT record types.
+type NetResolver struct {
+	Resolver *net.Resolver
+}
+
+// Lookup implements Resolver.
+func (r NetResolver) Lookup(ctx context.Context, name, recordType string) ([]libdns.RR, error) {
+	resolver := r.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	name = dns.Fqdn(name)
+	var records []libdns.RR
+	add := func(data string) {
+		records = append(records, libdns.RR{Name: name, Type: recordType, Data: data})
+	}
+	var err error
+	switch strings.ToUpper(recordType) {
+	case "A", "AAAA":
+		network := "ip4"
+		if strings.EqualFold(recordType, "AAAA") {
+			network = "ip6"
+		}
+		ips, lookupErr := resolver.LookupNetIP(ctx, network, name)
+		for _, ip := range ips {
+			add(ip.Unmap().String())
+		}
+		err = lookupErr
+	case "CNAME":
+		var target string
+		if target, err = resolver.LookupCNAME(ctx, name); err == nil && !strings.EqualFold(target, name) {
+			add(target)
+		}
+	case "MX":
+		mxs, lookupErr := resolver.LookupMX(ctx, name)
+		for _, mx := range mxs {
+			add(fmt.Sprintf("%d %s", mx.Pref, mx.Host))
+		}
+		err = lookupErr
+	case "NS":
+		nss, lookupErr := resolver.LookupNS(ctx, name)
+		for _, ns := range nss {
+			add(ns.Host)
+		}
+		err = lookupErr
+	case "SRV":
+		_, srvs, lookupErr := resolver.LookupSRV(ctx, "", "", name)
+		for _, srv := range srvs {
+			add(fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, srv.Target))
+		}
+		err = lookupErr
+	case "TXT":
+		txts, lookupErr := resolver.LookupTXT(ctx, name)
+		for _, txt := range txts {
+			add(txt)
+		}
+		err = lookupErr
+	default:
+		return nil, fmt.Errorf("%w: %s lookups are not supported by NetResolver", ErrUnsupportedRecordType, recordType)
+	}
+	if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+		err = nil
+	}
+	return records, err
+}
+
+// NameserverResolver is a Resolver that queries a nameserver directly, such
+// as one of Linode's authoritative nameservers.
+type NameserverResolver struct {
+	// Server is the address of the nameserver, e.g. "ns1.linode.com:53". The
+	// port defaults to 53.
+	Server string
+}
+
+// Lookup implements Resolver.
+func (r NameserverResolver) Lookup(ctx context.Context, name, recordType string) ([]libdns.RR, error) {
+	server := r.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	query, err := newQuery(name, recordType)
+	if err != nil {
+		return nil, err
+	}
+	query.RecursionDesired = false
+	resp, _, err := new(dns.Client).ExchangeContext(ctx, query, server)
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %v", server, err)
+	}
+	return answerRecords(resp)
+}
+
+// DoHResolver is a Resolver using DNS over HTTPS (RFC 8484).
+type DoHResolver struct {
+	// URL is the DoH endpoint, e.g. "https://cloudflare-dns.com/dns-query".
+	URL string
+	// Client makes the requests; http.DefaultClient by default.
+	Client *http.Client
+}
+
+// Lookup implements Resolver.
+func (r DoHResolver) Lookup(ctx context.Context, name, recordType string) ([]libdns.RR, error) {
+	query, err := newQuery(name, recordType)
+	if err != nil {
+		return nil, err
+	}
+	// RFC 8484 recommends an ID of 0 for cache friendliness.
+	query.Id = 0
+	body, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	httpResp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %v", r.URL, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not query %s: %s", r.URL, httpResp.Status)
+	}
+	wire, err := io.ReadAll(io.LimitReader(httpResp.Body, dns.MaxMsgSize))
+	if err != nil {
+		return nil, fmt.Errorf("could not query %s: %v", r.URL, err)
+	}
+	resp := new(dns.Msg)
+	if err := resp.Unpack(wire); err != nil {
+		return nil, fmt.Errorf("could not parse response of %s: %v", r.URL, err)
+	}
+	return answerRecords(resp)
+}
+
+// newQuery returns a query for the records of the given type at name.
+func newQuery(name, recordType string) (*dns.Msg, error) {
+	qtype, ok := dns.StringToType[strings.ToUpper(recordType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedRecordType, recordType)
+	}
+	query := new(dns.Msg)
+	query.SetQuestion(dns.Fqdn(name), qtype)
+	return query, nil
+}
+
+// answerRecords returns the answers of a response with the queried type.
+// Answers of other types, such as the CNAMEs leading to them, are skipped.
+func answerRecords(resp *dns.Msg) ([]libdns.RR, error) {
+	if resp.Rcode != dns.RcodeSuccess && resp.Rcode != dns.RcodeNameError {
+		return nil, fmt.Errorf("lookup failed: %s", dns.RcodeToString[resp.Rcode])
+	}
+	var qtype uint16
+	if len(resp.Question) > 0 {
+		qtype = resp.Question[0].Qtype
+	}
+	var records []libdns.RR
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype != qtype {
+			continue
+		}
+		record := rrFromDNS(rr)
+		record.Name = rr.Header().Name
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// rrFromDNS converts a miekg/dns resource record. Its name is left empty.
+func rrFromDNS(rr dns.RR) libdns.RR {
+	header := rr.Header()
+	data := strings.TrimSpace(strings.TrimPrefix(rr.String(), header.String()))
+	if txt, ok := rr.(*dns.TXT); ok {
+		data = strings.Join(txt.Txt, "")
+	}
+	return libdns.RR{
+		TTL:  time.Duration(header.Ttl) * time.Second,
+		Type: dns.TypeToString[header.Rrtype],
+		Data: data,
+	}
+}
+
+// resolver returns the configured Resolver. Without one, it returns a
+// NameserverResolver querying the nameserver, or the system's resolver if
+// no nameserver is given.
+func (p *Provider) resolver(nameserver string) Resolver {
+	if p.Resolver != nil {
+		return p.Resolver
+	}
+	if nameserver != "" {
+		return NameserverResolver{Server: nameserver}
+	}
+	return NetResolver{}
+}
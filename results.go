@@ -0,0 +1,106 @@
+package linode
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// Outcome describes what a batch operation did with a record.
+type Outcome string
+
+const (
+	// OutcomeCreated means the record was created.
+	OutcomeCreated Outcome = "created"
+	// OutcomeUpdated means an existing record was updated.
+	OutcomeUpdated Outcome = "updated"
+	// OutcomeDeleted means the record was deleted.
+	OutcomeDeleted Outcome = "deleted"
+	// OutcomeSkipped means the record was not processed because the batch
+	// was aborted by an earlier failure.
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeNoOp means the zone already matched the record, so no change was needed.
+	OutcomeNoOp Outcome = "no-op"
+	// OutcomeFailed means processing the record failed, see RecordResult.Err.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeRolledBack means the record was written, but the write was
+	// undone because a later write that had to succeed with it failed.
+	OutcomeRolledBack Outcome = "rolled-back"
+)
+
+// RecordResult is the outcome of a batch operation for a single input record.
+type RecordResult struct {
+	// Record is the record as returned by Linode, or the input record if it
+	// was skipped or failed.
+	Record libdns.Record
+	// Outcome is what happened to the record.
+	Outcome Outcome
+	// Err is set when Outcome is OutcomeFailed.
+	Err error
+}
+
+// AppendRecordsWithResults behaves like AppendRecords, but returns a result for
+// every input record, in the same order as the input.
+func (p *Provider) AppendRecordsWithResults(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	return p.inParentZone(ctx, zone, records, p.appendRecords)
+}
+
+// SetRecordsWithResults behaves like SetRecords, but returns a result for
+// every input record, in the same order as the input, followed by a result
+// for every record SetRecords removed from the RRsets it replaced.
+func (p *Provider) SetRecordsWithResults(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	return p.inParentZone(ctx, zone, records, p.setRecords)
+}
+
+// DeleteRecordsWithResults behaves like DeleteRecords, but returns a result for
+// every input record, in the same order as the input, followed by a result
+// for every further record deleted by an input record without a Linode ID
+// that matched several records. The result of such an input record holds
+// the first record it matched.
+func (p *Provider) DeleteRecordsWithResults(ctx context.Context, zone string, records []libdns.Record) ([]RecordResult, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	return p.inParentZone(ctx, zone, records, p.deleteRecords)
+}
+
+// newRecordResults returns results for the records, all initially skipped.
+func newRecordResults(records []libdns.Record) []RecordResult {
+	results := make([]RecordResult, len(records))
+	for i, record := range records {
+		results[i] = RecordResult{Record: record, Outcome: OutcomeSkipped}
+	}
+	return results
+}
+
+// resultRecords converts the results of a batch operation to the return values
+// of the libdns interfaces, keeping the records with one of the given outcomes.
+// Errors carry the results as a *BatchError.
+func (p *Provider) resultRecords(results []RecordResult, err error, outcomes ...Outcome) ([]libdns.Record, error) {
+	err = newBatchError(results, err)
+	if err != nil && !p.ContinueOnError {
+		return nil, err
+	}
+	records := make([]libdns.Record, 0, len(results))
+	for _, result := range results {
+		for _, outcome := range outcomes {
+			if result.Outcome == outcome {
+				records = append(records, result.Record)
+				break
+			}
+		}
+	}
+	return records, err
+}
@@ -0,0 +1,21 @@
+package linode
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// retryTransient reports whether a request failed in a way that may succeed
+// when retried: with a network error, or with a server error other than
+// Linode's maintenance mode.
+func retryTransient(resp *resty.Response, err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return err == nil && resp != nil && resp.StatusCode() >= http.StatusInternalServerError &&
+		resp.Header().Get("X-Maintenance-Mode") == ""
+}
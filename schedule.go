@@ -0,0 +1,209 @@
+package linode
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// ScheduledChange is a set of record changes to apply to a zone at a given time.
+type ScheduledChange struct {
+	// ID identifies the change; it is assigned by ScheduleChange if empty.
+	ID string
+	// Zone is the zone to change.
+	Zone string
+	// ApplyAt is when the change is applied.
+	ApplyAt time.Time
+	// Delete, Set and Append are passed to DeleteRecords, SetRecords and
+	// AppendRecords respectively, in that order.
+	Delete []libdns.Record
+	Set    []libdns.Record
+	Append []libdns.Record
+}
+
+// ScheduleStore persists scheduled changes, so that they survive restarts of
+// the scheduler.
+type ScheduleStore interface {
+	// Save stores a new scheduled change.
+	Save(ctx context.Context, change ScheduledChange) error
+	// Remove removes a change that was applied or canceled.
+	Remove(ctx context.Context, id string) error
+	// Load returns all stored changes.
+	Load(ctx context.Context) ([]ScheduledChange, error)
+}
+
+// Scheduler applies scheduled record changes when they are due, for example to
+// stage a cut-over in advance. Changes are applied while Run is running.
+type Scheduler struct {
+	// Provider applies the changes.
+	Provider *Provider
+	// Store optionally persists the scheduled changes.
+	Store ScheduleStore
+	// OnApplied is called after a change was applied, with the error if the
+	// change failed. Failed changes are not retried.
+	OnApplied func(change ScheduledChange, err error)
+
+	mutex   sync.Mutex
+	changes map[string]ScheduledChange
+	wake    chan struct{}
+}
+
+// ScheduleChange schedules the change to be applied at applyAt and returns its ID.
+func (s *Scheduler) ScheduleChange(ctx context.Context, applyAt time.Time, change ScheduledChange) (string, error) {
+	if change.ID == "" {
+		id := make([]byte, 8)
+		if _, err := rand.Read(id); err != nil {
+			return "", err
+		}
+		change.ID = hex.EncodeToString(id)
+	}
+	change.ApplyAt = applyAt
+	if s.Store != nil {
+		if err := s.Store.Save(ctx, change); err != nil {
+			return "", fmt.Errorf("could not save scheduled change: %v", err)
+		}
+	}
+	s.mutex.Lock()
+	s.add(change)
+	s.mutex.Unlock()
+	s.notify()
+	return change.ID, nil
+}
+
+// Cancel removes a scheduled change that was not applied yet.
+func (s *Scheduler) Cancel(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	_, ok := s.changes[id]
+	delete(s.changes, id)
+	s.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("no scheduled change with ID %s", id)
+	}
+	if s.Store != nil {
+		return s.Store.Remove(ctx, id)
+	}
+	return nil
+}
+
+// Pending returns the changes that were not applied yet, ordered by time.
+func (s *Scheduler) Pending() []ScheduledChange {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pending := make([]ScheduledChange, 0, len(s.changes))
+	for _, change := range s.changes {
+		pending = append(pending, change)
+	}
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].ApplyAt.Before(pending[j].ApplyAt)
+	})
+	return pending
+}
+
+// Run loads the stored changes and applies changes as they become due, until
+// the context is canceled.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if s.Store != nil {
+		changes, err := s.Store.Load(ctx)
+		if err != nil {
+			return fmt.Errorf("could not load scheduled changes: %v", err)
+		}
+		s.mutex.Lock()
+		for _, change := range changes {
+			s.add(change)
+		}
+		s.mutex.Unlock()
+	}
+	for {
+		for _, change := range s.due(time.Now()) {
+			err := s.apply(ctx, change)
+			if s.Store != nil {
+				if removeErr := s.Store.Remove(ctx, change.ID); err == nil && removeErr != nil {
+					err = fmt.Errorf("change was applied, but could not be removed from the store: %v", removeErr)
+				}
+			}
+			if s.OnApplied != nil {
+				s.OnApplied(change, err)
+			}
+		}
+		wait := time.Hour
+		if pending := s.Pending(); len(pending) > 0 {
+			wait = time.Until(pending[0].ApplyAt)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.wakeChan():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// add adds a change. The caller must hold s.mutex.
+func (s *Scheduler) add(change ScheduledChange) {
+	if s.changes == nil {
+		s.changes = make(map[string]ScheduledChange)
+	}
+	s.changes[change.ID] = change
+}
+
+// due removes and returns the changes due at now, ordered by time.
+func (s *Scheduler) due(now time.Time) []ScheduledChange {
+	var due []ScheduledChange
+	for _, change := range s.Pending() {
+		if change.ApplyAt.After(now) {
+			break
+		}
+		due = append(due, change)
+	}
+	s.mutex.Lock()
+	for _, change := range due {
+		delete(s.changes, change.ID)
+	}
+	s.mutex.Unlock()
+	return due
+}
+
+func (s *Scheduler) apply(ctx context.Context, change ScheduledChange) error {
+	if len(change.Delete) > 0 {
+		if _, err := s.Provider.DeleteRecords(ctx, change.Zone, change.Delete); err != nil {
+			return err
+		}
+	}
+	if len(change.Set) > 0 {
+		if _, err := s.Provider.SetRecords(ctx, change.Zone, change.Set); err != nil {
+			return err
+		}
+	}
+	if len(change.Append) > 0 {
+		if _, err := s.Provider.AppendRecords(ctx, change.Zone, change.Append); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) wakeChan() chan struct{} {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.wake == nil {
+		s.wake = make(chan struct{}, 1)
+	}
+	return s.wake
+}
+
+// notify wakes up Run to reconsider the next due change.
+func (s *Scheduler) notify() {
+	select {
+	case s.wakeChan() <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,56 @@
+package linode
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// checkScope returns an error wrapping ErrOutOfScope for the first record whose
+// name is not matched by AllowedNames, or whose type is not allowed by
+// AllowedTypes and DeniedTypes.
+func (p *Provider) checkScope(zone string, records []libdns.Record) error {
+	if len(p.AllowedNames) == 0 && len(p.AllowedTypes) == 0 && len(p.DeniedTypes) == 0 {
+		return nil
+	}
+	for i, record := range records {
+		rr := record.RR()
+		if name := normalizeName(zone, rr.Name); len(p.AllowedNames) > 0 && !p.nameAllowed(name) {
+			return &RecordError{Index: i, Record: record, Err: fmt.Errorf("%w: %s", ErrOutOfScope, name)}
+		}
+		if !p.typeAllowed(rr.Type) {
+			return &RecordError{Index: i, Record: record, Err: fmt.Errorf("%w: type %s", ErrOutOfScope, rr.Type)}
+		}
+	}
+	return nil
+}
+
+// nameAllowed reports whether the normalized name matches one of AllowedNames.
+func (p *Provider) nameAllowed(name string) bool {
+	for _, pattern := range p.AllowedNames {
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// typeAllowed reports whether records of the type may be written.
+func (p *Provider) typeAllowed(recordType string) bool {
+	for _, denied := range p.DeniedTypes {
+		if strings.EqualFold(denied, recordType) {
+			return false
+		}
+	}
+	if len(p.AllowedTypes) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedTypes {
+		if strings.EqualFold(allowed, recordType) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/linode/linodego"
+)
+
+// GetZoneMasterIPs returns the primary nameservers a secondary zone is
+// transferred from. It is empty for master zones.
+func (p *Provider) GetZoneMasterIPs(ctx context.Context, zone string) ([]netip.Addr, error) {
+	domain, err := p.zoneDomain(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return parseIPs(domain.MasterIPs)
+}
+
+// SetZoneMasterIPs replaces the primary nameservers of a secondary zone. Use
+// CreateZone with MasterIPs to create a secondary zone.
+func (p *Provider) SetZoneMasterIPs(ctx context.Context, zone string, ips []netip.Addr) error {
+	_, err := p.updateZoneDomain(ctx, zone, func(opts *linodego.DomainUpdateOptions) {
+		opts.MasterIPs = ipStrings(ips)
+	})
+	return err
+}
+
+func ipStrings(ips []netip.Addr) []string {
+	s := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		s = append(s, ip.String())
+	}
+	return s
+}
+
+func parseIPs(s []string) ([]netip.Addr, error) {
+	ips := make([]netip.Addr, 0, len(s))
+	for _, ip := range s {
+		addr, err := netip.ParseAddr(ip)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP address %q: %v", ip, err)
+		}
+		ips = append(ips, addr)
+	}
+	return ips, nil
+}
@@ -0,0 +1,60 @@
+package linode
+
+import (
+	"context"
+	"time"
+
+	"github.com/linode/linodego"
+)
+
+// SOASettings are the Start of Authority parameters of a zone, which Linode
+// stores on the domain instead of as a record. A zero duration means Linode's
+// default.
+type SOASettings struct {
+	Email   string        `json:"email"`
+	Refresh time.Duration `json:"refresh,omitempty"`
+	Retry   time.Duration `json:"retry,omitempty"`
+	Expire  time.Duration `json:"expire,omitempty"`
+}
+
+// GetSOA returns the SOA settings of the zone.
+func (p *Provider) GetSOA(ctx context.Context, zone string) (SOASettings, error) {
+	domain, err := p.zoneDomain(ctx, zone)
+	if err != nil {
+		return SOASettings{}, err
+	}
+	return soaSettings(domain), nil
+}
+
+// SetSOA changes the SOA settings of the zone and returns the settings Linode
+// stored. Empty fields are left unchanged, and durations are rounded up to
+// the values Linode accepts, the same as for TTLs.
+func (p *Provider) SetSOA(ctx context.Context, zone string, settings SOASettings) (SOASettings, error) {
+	domain, err := p.updateZoneDomain(ctx, zone, func(opts *linodego.DomainUpdateOptions) {
+		if settings.Email != "" {
+			opts.SOAEmail = settings.Email
+		}
+		if settings.Refresh > 0 {
+			opts.RefreshSec = int(roundTTL(settings.Refresh).Seconds())
+		}
+		if settings.Retry > 0 {
+			opts.RetrySec = int(roundTTL(settings.Retry).Seconds())
+		}
+		if settings.Expire > 0 {
+			opts.ExpireSec = int(roundTTL(settings.Expire).Seconds())
+		}
+	})
+	if err != nil {
+		return SOASettings{}, err
+	}
+	return soaSettings(domain), nil
+}
+
+func soaSettings(domain *linodego.Domain) SOASettings {
+	return SOASettings{
+		Email:   domain.SOAEmail,
+		Refresh: time.Duration(domain.RefreshSec) * time.Second,
+		Retry:   time.Duration(domain.RetrySec) * time.Second,
+		Expire:  time.Duration(domain.ExpireSec) * time.Second,
+	}
+}
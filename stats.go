@@ -0,0 +1,140 @@
+package linode
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats are counters of the provider's activity since it was first used.
+type Stats struct {
+	// Since is when the provider was first used.
+	Since time.Time `json:"since"`
+	// APICalls counts the API requests by operation, such as
+	// "GET domains/{id}/records", not counting retries.
+	APICalls map[string]int64 `json:"api_calls"`
+	// Retries counts the API requests that were retried.
+	Retries int64 `json:"retries"`
+	// CacheHits and CacheMisses count the GetRecords calls that were and were
+	// not answered from the record cache while it is enabled.
+	CacheHits   int64 `json:"cache_hits"`
+	CacheMisses int64 `json:"cache_misses"`
+	// RateLimitWaits counts the API requests the client-side rate limiter
+	// delayed, and RateLimitWaitTime the total delay.
+	RateLimitWaits    int64         `json:"rate_limit_waits"`
+	RateLimitWaitTime time.Duration `json:"rate_limit_wait_time"`
+	// Errors counts the failed API requests by class: "network",
+	// "rate_limited", "client" or "server".
+	Errors map[string]int64 `json:"errors"`
+}
+
+// CacheHitRate returns the share of GetRecords calls answered from the cache.
+func (s Stats) CacheHitRate() float64 {
+	if s.CacheHits+s.CacheMisses == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(s.CacheHits+s.CacheMisses)
+}
+
+// statsCounters holds the counters behind Stats. It has its own mutex, since
+// requests are counted while p.mutex is held.
+type statsCounters struct {
+	mutex sync.Mutex
+	stats Stats
+}
+
+func (c *statsCounters) update(f func(*Stats)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.stats.Since.IsZero() {
+		c.stats.Since = time.Now()
+		c.stats.APICalls = make(map[string]int64)
+		c.stats.Errors = make(map[string]int64)
+	}
+	f(&c.stats)
+}
+
+// Stats returns a snapshot of the provider's counters.
+func (p *Provider) Stats() Stats {
+	var snapshot Stats
+	p.stats.update(func(s *Stats) {
+		snapshot = *s
+		snapshot.APICalls = make(map[string]int64, len(s.APICalls))
+		for op, n := range s.APICalls {
+			snapshot.APICalls[op] = n
+		}
+		snapshot.Errors = make(map[string]int64, len(s.Errors))
+		for class, n := range s.Errors {
+			snapshot.Errors[class] = n
+		}
+	})
+	return snapshot
+}
+
+// countRequest counts an API request attempt.
+func (p *Provider) countRequest(method, url string, attempt int) {
+	p.stats.update(func(s *Stats) {
+		if attempt > 1 {
+			s.Retries++
+			return
+		}
+		s.APICalls[method+" "+apiOperation(url)]++
+	})
+}
+
+// apiOperation replaces the IDs in an API path with "{id}" and removes the API
+// version, such as "v4", from its start.
+func apiOperation(url string) string {
+	if i := strings.IndexAny(url, "?#"); i >= 0 {
+		url = url[:i]
+	}
+	segments := strings.Split(strings.Trim(url, "/"), "/")
+	if len(segments) > 1 && isAPIVersion(segments[0]) {
+		segments = segments[1:]
+	}
+	for i, segment := range segments {
+		if segment != "" && strings.Trim(segment, "0123456789") == "" {
+			segments[i] = "{id}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// isAPIVersion reports whether the path segment is an API version, such as
+// "v4" or "v4beta".
+func isAPIVersion(segment string) bool {
+	return len(segment) > 1 && segment[0] == 'v' && segment[1] >= '0' && segment[1] <= '9'
+}
+
+// statsTransport counts the failed API requests by class, and reports every
+// request to Logger, Metrics and Tracer.
+type statsTransport struct {
+	provider *Provider
+	base     http.RoundTripper
+}
+
+func (t *statsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, endSpan := t.provider.traceAPICall(req)
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	duration := time.Since(start)
+	endSpan(resp, err)
+	t.provider.logAPICall(req, resp, duration, err)
+	t.provider.measureAPICall(req, resp, duration, err)
+	class := ""
+	switch {
+	case err != nil:
+		class = "network"
+	case resp.StatusCode == http.StatusTooManyRequests:
+		class = "rate_limited"
+	case resp.StatusCode >= 500:
+		class = "server"
+	case resp.StatusCode >= 400:
+		class = "client"
+	}
+	if class != "" {
+		t.provider.stats.update(func(s *Stats) { s.Errors[class]++ })
+	}
+	return resp, err
+}
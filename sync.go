@@ -0,0 +1,63 @@
+package linode
+
+import (
+	"context"
+
+	"github.com/libdns/libdns"
+)
+
+// SyncOptions configures SyncZone.
+type SyncOptions struct {
+	// Prune deletes the records of the zone whose name and type do not appear
+	// in the desired records. Without it, only the RRsets of the desired
+	// records are changed and other records are left alone.
+	Prune bool
+	// Managed reports whether a record may be pruned. By default all records
+	// may be, except those outside of AllowedNames and AllowedTypes, which
+	// are always kept.
+	Managed func(zone string, record libdns.Record) bool
+}
+
+// SyncZone makes the zone match the desired records with the fewest writes,
+// for managing zones declaratively from version control. Like SetRecords,
+// every RRset of the desired records ends up with exactly those records:
+// records with the same data are kept, others are updated in place, created
+// or deleted. With Prune, the records of other RRsets are deleted too. It
+// returns a result for every desired record, in the same order, followed by
+// a result for every deleted record.
+func (p *Provider) SyncZone(ctx context.Context, zone string, desired []libdns.Record, opts SyncOptions) ([]RecordResult, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	var prune func(libdns.Record) bool
+	if opts.Prune {
+		prune = func(record libdns.Record) bool {
+			if p.checkScope(zone, []libdns.Record{record}) != nil {
+				return false
+			}
+			return opts.Managed == nil || opts.Managed(zone, record)
+		}
+	}
+	return p.reconcile(ctx, zone, desired, "sync", prune)
+}
+
+// planPrune returns the existing records outside of the RRsets of the desired
+// records that prune selects. Records without a Linode ID are ignored.
+func (p *Provider) planPrune(zone string, existing, desired []libdns.Record, prune func(libdns.Record) bool) []libdns.Record {
+	keep := make(map[rrsetKey]bool, len(desired))
+	for _, record := range desired {
+		keep[newRRSetKey(zone, record)] = true
+	}
+	var deletions []libdns.Record
+	for _, record := range existing {
+		if _, ok := recordID(record); !ok || keep[newRRSetKey(zone, record)] {
+			continue
+		}
+		if prune(record) {
+			deletions = append(deletions, record)
+		}
+	}
+	return deletions
+}
@@ -0,0 +1,54 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linode/linodego"
+)
+
+// TokenSource supplies the Linode API token. It is asked before every
+// request, so that tokens rotated in Vault, a Kubernetes secret or a file are
+// picked up without recreating the Provider.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc is a TokenSource implemented by a function.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// FileTokenSource reads the token from a file, such as a mounted Kubernetes
+// secret, every time it is asked for it.
+type FileTokenSource struct {
+	Path string
+}
+
+// Token returns the contents of the file without surrounding whitespace.
+func (s FileTokenSource) Token(context.Context) (string, error) {
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		return "", fmt.Errorf("could not read API token: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// authorize sets the Authorization header of the request to the token from
+// TokenSource, if set.
+func (p *Provider) authorize(r *linodego.Request) error {
+	if p.TokenSource == nil {
+		return nil
+	}
+	token, err := p.TokenSource.Token(r.Context())
+	if err != nil {
+		return err
+	}
+	r.SetHeader("Authorization", "Bearer "+token)
+	return nil
+}
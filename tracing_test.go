@@ -0,0 +1,95 @@
+package linode_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+type span struct {
+	name       string
+	parent     string
+	attributes map[string]string
+	ended      bool
+	err        error
+}
+
+type spanKey struct{}
+
+// spanRecorder is a linode.Tracer recording the spans it starts.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+func (r *spanRecorder) StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, func(error)) {
+	s := &span{name: name, attributes: attributes}
+	if parent, ok := ctx.Value(spanKey{}).(*span); ok {
+		s.parent = parent.name
+	}
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), func(err error) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		s.ended, s.err = true, err
+	}
+}
+
+func TestTracerRecordOperations(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	tracer := new(spanRecorder)
+	linode.WithTracer(tracer)(provider)
+
+	mustAppend(t, provider, libdns.TXT{Name: "a", Text: "one"}, libdns.TXT{Name: "b", Text: "two"})
+	if _, err := provider.DeleteRecords(context.Background(), "missing.example.", []libdns.Record{libdns.TXT{Name: "a"}}); err == nil {
+		t.Fatal("DeleteRecords in a missing zone succeeded")
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("got %d spans, want 2", len(tracer.spans))
+	}
+	appendSpan, deleteSpan := tracer.spans[0], tracer.spans[1]
+	if appendSpan.name != "linode.AppendRecords" || appendSpan.attributes["dns.zone"] != testZone || appendSpan.attributes["dns.records"] != "2" {
+		t.Errorf("AppendRecords span = %s %v", appendSpan.name, appendSpan.attributes)
+	}
+	if !appendSpan.ended || appendSpan.err != nil {
+		t.Errorf("AppendRecords span ended = %t with %v, want ended without error", appendSpan.ended, appendSpan.err)
+	}
+	if deleteSpan.name != "linode.DeleteRecords" || !deleteSpan.ended || deleteSpan.err == nil {
+		t.Errorf("DeleteRecords span %s ended = %t with %v, want ended with an error", deleteSpan.name, deleteSpan.ended, deleteSpan.err)
+	}
+}
+
+func TestTracerAPICalls(t *testing.T) {
+	provider := replay(t, "unauthorized.json").Provider()
+	tracer := new(spanRecorder)
+	provider.Tracer = tracer
+
+	if _, err := provider.GetRecords(context.Background(), testZone); err == nil {
+		t.Fatal("GetRecords succeeded")
+	}
+
+	if len(tracer.spans) < 2 {
+		t.Fatalf("got %d spans, want the GetRecords span and its API calls", len(tracer.spans))
+	}
+	if tracer.spans[0].name != "linode.GetRecords" {
+		t.Errorf("first span = %s, want linode.GetRecords", tracer.spans[0].name)
+	}
+	for _, s := range tracer.spans[1:] {
+		if !strings.HasPrefix(s.name, "linode GET ") || s.attributes["linode.operation"] == "" {
+			t.Errorf("API call span = %s %v", s.name, s.attributes)
+		}
+		if s.parent != "linode.GetRecords" {
+			t.Errorf("API call span %s has parent %q, want linode.GetRecords", s.name, s.parent)
+		}
+		if !s.ended || s.err == nil {
+			t.Errorf("API call span %s ended = %t with %v, want ended with an error", s.name, s.ended, s.err)
+		}
+	}
+}
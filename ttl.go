@@ -0,0 +1,119 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// RecordSelector selects records of a zone by name and type. Empty fields
+// select all records.
+type RecordSelector struct {
+	// Names are patterns in the syntax of path.Match that the names of the
+	// selected records, relative to the zone, must match one of.
+	Names []string
+	// Types are the types the selected records must have one of.
+	Types []string
+}
+
+// matches reports whether the record of the zone is selected.
+func (s RecordSelector) matches(zone string, record libdns.Record) bool {
+	rr := record.RR()
+	if !hasType(s.Types, rr.Type) {
+		return false
+	}
+	if len(s.Names) == 0 {
+		return true
+	}
+	name := normalizeName(zone, rr.Name)
+	for _, pattern := range s.Names {
+		if ok, _ := path.Match(normalizeName(zone, pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TTLUpdateOptions configures UpdateTTLs.
+type TTLUpdateOptions struct {
+	// DryRun only reports the records that would be updated, without
+	// changing the zone.
+	DryRun bool
+}
+
+// UpdateTTLs sets the TTL of all records of the zone selected by selector to
+// ttl, such as lowering every TTL to 5 minutes before a migration. The TTL is
+// rounded up to one Linode accepts, and records that already have the rounded
+// TTL are left alone. It returns a result for each record to update; with
+// DryRun, the results report the updates that would be made.
+func (p *Provider) UpdateTTLs(ctx context.Context, zone string, selector RecordSelector, ttl time.Duration, opts TTLUpdateOptions) ([]RecordResult, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("%w: TTL must be positive", ErrInvalidRecord)
+	}
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	dryRun := opts.DryRun || isDryRun(ctx)
+	if !dryRun {
+		if err := p.checkWritable(zone); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	existing, err := p.listDomainRecords(ctx, zone, domainID)
+	if err != nil {
+		return nil, err
+	}
+	ttl = roundTTL(ttl)
+	var selected []libdns.Record
+	for _, record := range existing {
+		if selector.matches(zone, record) && record.RR().TTL != ttl {
+			selected = append(selected, record)
+		}
+	}
+	if err := p.checkScope(zone, selected); err != nil {
+		return nil, err
+	}
+	if dryRun {
+		results := make([]RecordResult, len(selected))
+		for i, record := range selected {
+			results[i] = RecordResult{Record: withTTL(record, ttl), Outcome: OutcomeUpdated}
+		}
+		return results, nil
+	}
+	results := newRecordResults(selected)
+	defer p.invalidateRecords(zone)
+	defer p.notifyWebhook(zone, "update_ttl", results)
+	var batchErr BatchError
+	err = p.forEach(ctx, len(selected), func(i int) error {
+		record := selected[i]
+		updated, err := p.updateDomainRecord(ctx, zone, domainID, withTTL(record, ttl), record)
+		if err != nil {
+			results[i] = RecordResult{Record: record, Outcome: OutcomeFailed, Err: err}
+			return p.handleRecordError(&batchErr, i, record, err)
+		}
+		results[i] = RecordResult{Record: updated, Outcome: OutcomeUpdated}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, batchErr.errOrNil()
+}
@@ -0,0 +1,70 @@
+package linode_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+	"github.com/linode/linodego"
+)
+
+// failingUpdateAPI fails every record update.
+type failingUpdateAPI struct {
+	linode.DomainAPI
+}
+
+func (api failingUpdateAPI) UpdateDomainRecord(context.Context, int, int, linodego.DomainRecordUpdateOptions) (*linodego.DomainRecord, error) {
+	return nil, &linodego.Error{Code: 500, Message: "update failed"}
+}
+
+func TestUpdateTTLsRoundsTTL(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	mustAppend(t, provider, libdns.TXT{Name: "a", TTL: 5 * time.Minute, Text: "a"}, libdns.TXT{Name: "b", TTL: time.Hour, Text: "b"})
+	// Linode stores 4 minutes as 5 minutes, so only b is updated.
+	results, err := provider.UpdateTTLs(context.Background(), testZone, linode.RecordSelector{}, 4*time.Minute, linode.TTLUpdateOptions{})
+	if err != nil {
+		t.Fatalf("UpdateTTLs: %v", err)
+	}
+	if len(results) != 1 || results[0].Record.RR().Name != "b" || results[0].Outcome != linode.OutcomeUpdated {
+		t.Fatalf("results = %+v, want b updated", results)
+	}
+	for _, record := range mustGet(t, provider) {
+		if ttl := record.RR().TTL; ttl != 5*time.Minute {
+			t.Errorf("%s has TTL %s, want 5m", record.RR().Name, ttl)
+		}
+	}
+}
+
+func TestUpdateTTLsDryRun(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	mustAppend(t, provider, libdns.TXT{Name: "a", TTL: time.Hour, Text: "a"})
+	results, err := provider.UpdateTTLs(context.Background(), testZone, linode.RecordSelector{}, 5*time.Minute, linode.TTLUpdateOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("UpdateTTLs: %v", err)
+	}
+	if len(results) != 1 || results[0].Outcome != linode.OutcomeUpdated || results[0].Record.RR().TTL != 5*time.Minute {
+		t.Fatalf("results = %+v, want a planned update to 5m", results)
+	}
+	if ttl := mustGet(t, provider)[0].RR().TTL; ttl != time.Hour {
+		t.Errorf("dry run changed the TTL to %s", ttl)
+	}
+}
+
+func TestUpdateTTLsSkipsAfterFailure(t *testing.T) {
+	fake := new(linodetest.Fake)
+	fake.AddDomain("example.com")
+	provider := &linode.Provider{API: failingUpdateAPI{DomainAPI: fake}}
+	mustAppend(t, provider, libdns.TXT{Name: "a", TTL: time.Hour, Text: "a"}, libdns.TXT{Name: "b", TTL: time.Hour, Text: "b"})
+	results, err := provider.UpdateTTLs(context.Background(), testZone, linode.RecordSelector{}, 5*time.Minute, linode.TTLUpdateOptions{})
+	if err == nil {
+		t.Fatal("UpdateTTLs succeeded, want the update error")
+	}
+	want := []linode.Outcome{linode.OutcomeFailed, linode.OutcomeSkipped}
+	if got := outcomes(results); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("outcomes = %v, want %v", got, want)
+	}
+}
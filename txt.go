@@ -0,0 +1,92 @@
+package linode
+
+import (
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// maxTXTStringLength is the length limit of a single character-string of a
+// TXT record.
+const maxTXTStringLength = 255
+
+// txtEscaper escapes the text of a quoted character-string.
+var txtEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// splitQuotedStrings splits a TXT value made of quoted character-strings, such
+// as `"v=DKIM1; k=rsa; " "p=MIGf..."`, into the unquoted strings. It reports
+// false if the value is not entirely made of quoted strings.
+func splitQuotedStrings(value string) ([]string, bool) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, `"`) {
+		return nil, false
+	}
+	var strs []string
+	var b strings.Builder
+	inQuotes := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && inQuotes && i+1 < len(value):
+			i++
+			b.WriteByte(value[i])
+		case c == '"':
+			if inQuotes {
+				strs = append(strs, b.String())
+				b.Reset()
+			}
+			inQuotes = !inQuotes
+		case inQuotes:
+			b.WriteByte(c)
+		case c != ' ' && c != '\t':
+			// Text between the quoted strings.
+			return nil, false
+		}
+	}
+	if inQuotes {
+		return nil, false
+	}
+	return strs, true
+}
+
+// canonicalTXT returns the logical value of a TXT value given as one or more
+// quoted character-strings, as Linode returns some targets and as users copy
+// them from zone files, and other values unchanged. Canonicalizing both the
+// values read and those written keeps quoting from showing up as changes.
+func canonicalTXT(value string) string {
+	if strs, ok := splitQuotedStrings(value); ok {
+		return strings.Join(strs, "")
+	}
+	return value
+}
+
+// splitTXTString returns a TXT value longer than a single character-string,
+// such as a DKIM key, as quoted strings of at most 255 bytes each, and other
+// values unchanged.
+func splitTXTString(value string) string {
+	if len(value) <= maxTXTStringLength {
+		return value
+	}
+	var b strings.Builder
+	for len(value) > 0 {
+		n := maxTXTStringLength
+		if len(value) < n {
+			n = len(value)
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteByte('"')
+		b.WriteString(txtEscaper.Replace(value[:n]))
+		b.WriteByte('"')
+		value = value[n:]
+	}
+	return b.String()
+}
+
+// linodeTarget returns the target Linode stores for the record.
+func linodeTarget(rr libdns.RR) string {
+	if strings.EqualFold(rr.Type, "TXT") {
+		return splitTXTString(canonicalTXT(rr.Data))
+	}
+	return rr.Data
+}
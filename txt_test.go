@@ -0,0 +1,54 @@
+package linode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonicalTXT(t *testing.T) {
+	tests := []struct {
+		name, value, want string
+	}{
+		{"plain", "v=spf1 -all", "v=spf1 -all"},
+		{"quoted", `"v=spf1 -all"`, "v=spf1 -all"},
+		{"several strings", `"v=DKIM1; k=rsa; " "p=MIGf"`, "v=DKIM1; k=rsa; p=MIGf"},
+		{"strings without space", `"abc""def"`, "abcdef"},
+		{"surrounding space", `  "abc"  `, "abc"},
+		{"escaped quote", `"say \"hi\""`, `say "hi"`},
+		{"escaped backslash", `"a\\b"`, `a\b`},
+		{"empty string", `""`, ""},
+		{"text between strings", `"abc" x "def"`, `"abc" x "def"`},
+		{"unterminated", `"abc`, `"abc`},
+		{"quote inside", `say "hi"`, `say "hi"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := canonicalTXT(test.value); got != test.want {
+				t.Errorf("canonicalTXT(%q) = %q, want %q", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestSplitTXTString(t *testing.T) {
+	long := strings.Repeat("a", 300)
+	tests := []struct {
+		name, value, want string
+	}{
+		{"short", "v=spf1 -all", "v=spf1 -all"},
+		{"at the limit", strings.Repeat("a", 255), strings.Repeat("a", 255)},
+		{"long", long, `"` + strings.Repeat("a", 255) + `" "` + strings.Repeat("a", 45) + `"`},
+		{"long with quotes", `"` + long, `"\"` + strings.Repeat("a", 254) + `" "` + strings.Repeat("a", 46) + `"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitTXTString(test.value)
+			if got != test.want {
+				t.Errorf("splitTXTString = %q, want %q", got, test.want)
+			}
+			if canonical := canonicalTXT(got); canonical != test.value {
+				t.Errorf("canonicalTXT of the split value = %q, want %q", canonical, test.value)
+			}
+		})
+	}
+}
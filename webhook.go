@@ -0,0 +1,114 @@
+package linode
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/libdns/libdns"
+)
+
+// WebhookSignatureHeader is the header carrying the HMAC-SHA256 of the webhook
+// body, keyed with Provider.WebhookSecret, as "sha256=" followed by the
+// hex-encoded HMAC, like GitHub's X-Hub-Signature-256.
+const WebhookSignatureHeader = "X-Linode-DNS-Signature"
+
+// webhookTimeout bounds the delivery of a single webhook.
+const webhookTimeout = 10 * time.Second
+
+// WebhookPayload is the JSON body posted to Provider.WebhookURL after records
+// were changed, or when a DriftMonitor detected drift.
+type WebhookPayload struct {
+	Zone      string      `json:"zone"`
+	Operation string      `json:"operation"` // "append", "set", "delete", "apply", "sync", "update_ttl" or "drift"
+	Records   []libdns.RR `json:"records"`
+	Actor     string      `json:"actor,omitempty"`
+	Time      time.Time   `json:"time"`
+	// Missing and Unexpected are set for drift: the desired records that are
+	// missing from the zone, and the records that are not desired.
+	Missing    []libdns.RR `json:"missing,omitempty"`
+	Unexpected []libdns.RR `json:"unexpected,omitempty"`
+}
+
+// notifyWebhook posts the records that were changed by a batch operation to
+// the configured webhook in the background. Delivery is best-effort.
+func (p *Provider) notifyWebhook(zone, operation string, results []RecordResult) {
+	if p.WebhookURL == "" {
+		return
+	}
+	payload := WebhookPayload{
+		Zone:      zone,
+		Operation: operation,
+		Actor:     p.WebhookActor,
+		Time:      time.Now().UTC(),
+	}
+	for _, result := range results {
+		switch result.Outcome {
+		case OutcomeCreated, OutcomeUpdated, OutcomeDeleted:
+			payload.Records = append(payload.Records, result.Record.RR())
+		}
+	}
+	if len(payload.Records) == 0 {
+		return
+	}
+	go func() {
+		_ = p.postWebhook(payload)
+	}()
+}
+
+// notifyDrift posts the drift detected in a zone to the configured webhook in
+// the background. Delivery is best-effort.
+func (p *Provider) notifyDrift(drift Drift) {
+	if p.WebhookURL == "" {
+		return
+	}
+	payload := WebhookPayload{
+		Zone:      drift.Zone,
+		Operation: "drift",
+		Actor:     p.WebhookActor,
+		Time:      drift.Time.UTC(),
+	}
+	for _, record := range drift.Missing {
+		payload.Missing = append(payload.Missing, record.RR())
+	}
+	for _, record := range drift.Unexpected {
+		payload.Unexpected = append(payload.Unexpected, record.RR())
+	}
+	go func() {
+		_ = p.postWebhook(payload)
+	}()
+}
+
+func (p *Provider) postWebhook(payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.WebhookSecret != "" {
+		mac := hmac.New(sha256.New, []byte(p.WebhookSecret))
+		mac.Write(body)
+		req.Header.Set(WebhookSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
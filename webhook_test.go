@@ -0,0 +1,53 @@
+package linode_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/libdns/linode"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	type delivery struct {
+		signature string
+		body      []byte
+	}
+	deliveries := make(chan delivery, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		deliveries <- delivery{r.Header.Get(linode.WebhookSignatureHeader), body}
+	}))
+	defer server.Close()
+
+	p, _, _ := newTestProvider(t)
+	p.WebhookURL = server.URL
+	p.WebhookSecret = "secret"
+	mustAppend(t, p, libdns.TXT{Name: "www", Text: "hello"})
+
+	var d delivery
+	select {
+	case d = <-deliveries:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivered")
+	}
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(d.body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); d.signature != want {
+		t.Errorf("signature = %q, want %q", d.signature, want)
+	}
+	var payload linode.WebhookPayload
+	if err := json.Unmarshal(d.body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.Operation != "append" || len(payload.Records) != 1 {
+		t.Errorf("payload = %+v, want the appended record", payload)
+	}
+}
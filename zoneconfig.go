@@ -0,0 +1,79 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ZoneConfig overrides provider-wide settings for a single zone.
+type ZoneConfig struct {
+	// DomainID is the ID of the zone's Linode domain, which is then not
+	// looked up by name.
+	DomainID int `json:"domain_id,omitempty"`
+	// DefaultTTL is used for records of the zone that are written with a
+	// zero TTL, instead of Provider.DefaultTTL.
+	DefaultTTL time.Duration `json:"default_ttl,omitempty"`
+	// ReadOnly rejects all writes to the zone with ErrReadOnly.
+	ReadOnly bool `json:"read_only,omitempty"`
+	// RateLimit limits the API requests made for the zone to this many per
+	// RateLimitWindow (one minute by default), in addition to the
+	// provider-wide rate limit.
+	RateLimit       int           `json:"rate_limit,omitempty"`
+	RateLimitWindow time.Duration `json:"rate_limit_window,omitempty"`
+}
+
+// zoneConfig returns the overrides configured for the zone.
+func (p *Provider) zoneConfig(zone string) ZoneConfig {
+	key := zoneKey(zone)
+	for name, config := range p.Zones {
+		if zoneKey(name) == key {
+			return config
+		}
+	}
+	return ZoneConfig{}
+}
+
+// configuredDomainID returns the domain ID configured for the zone, if any.
+func (p *Provider) configuredDomainID(zone string) (int, bool) {
+	if id := p.zoneConfig(zone).DomainID; id > 0 {
+		return id, true
+	}
+	if p.DomainID > 0 && p.DomainZone != "" && zoneKey(p.DomainZone) == zoneKey(zone) {
+		return p.DomainID, true
+	}
+	return 0, false
+}
+
+// defaultTTL returns the TTL used for records of the zone written with a zero TTL.
+func (p *Provider) defaultTTL(zone string) time.Duration {
+	if ttl := p.zoneConfig(zone).DefaultTTL; ttl > 0 {
+		return ttl
+	}
+	return p.DefaultTTL
+}
+
+// checkWritable returns ErrReadOnly or ErrZoneFrozen if writes to the zone
+// are not allowed.
+func (p *Provider) checkWritable(zone string) error {
+	if p.ReadOnly || p.zoneConfig(zone).ReadOnly {
+		return ErrReadOnly
+	}
+	if until, ok := p.frozenUntil(zone); ok {
+		return fmt.Errorf("%w: %s until %s", ErrZoneFrozen, zone, until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+type zoneContextKey struct{}
+
+// withZone records the zone an operation is working on in the context, so that
+// per-zone settings can be applied to the API requests it makes.
+func withZone(ctx context.Context, zone string) context.Context {
+	return context.WithValue(ctx, zoneContextKey{}, zone)
+}
+
+func zoneFromContext(ctx context.Context) (string, bool) {
+	zone, ok := ctx.Value(zoneContextKey{}).(string)
+	return zone, ok
+}
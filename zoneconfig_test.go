@@ -0,0 +1,36 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/libdns/linode"
+	"github.com/libdns/linode/linodetest"
+)
+
+func TestDomainIDAppliesOnlyToDomainZone(t *testing.T) {
+	fake := new(linodetest.Fake)
+	domainID := fake.AddDomain("example.com")
+	fake.AddDomain("example.org")
+	provider := &linode.Provider{API: fake, DomainID: domainID, DomainZone: "example.com"}
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+
+	records, err := provider.GetRecords(context.Background(), "example.org.")
+	if err != nil {
+		t.Fatalf("GetRecords: %v", err)
+	}
+	if len(records) != 0 {
+		t.Fatalf("example.org. has %d records, want none", len(records))
+	}
+	if _, err := provider.GetRecords(context.Background(), "example.net."); !errors.Is(err, linode.ErrZoneNotFound) {
+		t.Fatalf("GetRecords of an unknown zone: %v, want ErrZoneNotFound", err)
+	}
+}
+
+func TestValidateDomainIDWithoutZone(t *testing.T) {
+	provider := &linode.Provider{API: new(linodetest.Fake), DomainID: 1}
+	if err := provider.Validate(); err == nil {
+		t.Fatal("Validate accepted DomainID without DomainZone")
+	}
+}
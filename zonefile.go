@@ -0,0 +1,68 @@
+package linode
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/libdns/libdns"
+)
+
+// ExportZoneFile returns the zone as Linode last rendered it for its
+// nameservers, in the standard (BIND) zone file format, for backups and
+// audits. Changes are rendered after a short delay, so very recent changes
+// may be missing.
+func (p *Provider) ExportZoneFile(ctx context.Context, zone string) (string, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return "", err
+	}
+	ctx = withZone(ctx, zone)
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return "", fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	zoneFile, err := p.api.GetDomainZoneFile(ctx, domainID)
+	if err != nil {
+		return "", fmt.Errorf("could not get zone file: %w", err)
+	}
+	if len(zoneFile.ZoneFile) == 0 {
+		return "", nil
+	}
+	return strings.Join(zoneFile.ZoneFile, "\n") + "\n", nil
+}
+
+// ParseZoneFile reads the records of the zone from a zone file in the standard
+// (BIND) format, such as one exported from another DNS provider. The SOA and
+// apex NS records are skipped, since Linode manages them.
+func ParseZoneFile(r io.Reader, zone string) ([]libdns.Record, error) {
+	records, err := parseZoneFile(r, zone)
+	if err != nil {
+		return nil, err
+	}
+	filtered := records[:0]
+	for _, record := range records {
+		if rr := record.RR(); rr.Type == "NS" && rr.Name == "@" {
+			continue
+		}
+		filtered = append(filtered, record)
+	}
+	return filtered, nil
+}
+
+// ImportZoneFile creates or updates the records of a zone file in the zone,
+// see ParseZoneFile. The RRsets of the file replace those of the zone, as with
+// SetRecords, and other records are left alone. It returns a result for every
+// record.
+func (p *Provider) ImportZoneFile(ctx context.Context, zone string, r io.Reader) ([]RecordResult, error) {
+	records, err := ParseZoneFile(r, zone)
+	if err != nil {
+		return nil, err
+	}
+	return p.SetRecordsWithResults(ctx, zone, records)
+}
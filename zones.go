@@ -0,0 +1,346 @@
+package linode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/libdns/libdns"
+	"github.com/linode/linodego"
+)
+
+// CreateZoneOptions configures CreateZone.
+type CreateZoneOptions struct {
+	// SOAEmail is the zone's Start of Authority email address, which Linode
+	// requires.
+	SOAEmail string
+	// TTL is the default TTL of the zone's records. Linode rounds it up to
+	// one of the TTLs it accepts, see Capabilities. If zero, Linode's default
+	// applies.
+	TTL time.Duration
+	// Presets are record bundles added to the new zone, see MailPreset and
+	// WebPreset.
+	Presets []ZonePreset
+	// MasterIPs, if set, makes the zone a secondary zone, which Linode
+	// transfers from these primary nameservers instead of serving records
+	// of its own. SOAEmail and Presets are then ignored.
+	MasterIPs []netip.Addr
+}
+
+// CreateZone creates a Linode domain for the zone and adds the records of the
+// presets to it. The domain is a master, unless MasterIPs are given.
+func (p *Provider) CreateZone(ctx context.Context, zone string, opts CreateZoneOptions) error {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.mutex.Unlock()
+	if err := p.checkWritable(zone); err != nil {
+		return err
+	}
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	ctx = withZone(ctx, zone)
+	createOpts := linodego.DomainCreateOptions{
+		Domain:   strings.TrimSuffix(zone, "."),
+		Type:     linodego.DomainTypeMaster,
+		SOAEmail: opts.SOAEmail,
+	}
+	if opts.TTL > 0 {
+		createOpts.TTLSec = int(roundTTL(opts.TTL).Seconds())
+	}
+	if len(opts.MasterIPs) > 0 {
+		createOpts.Type = linodego.DomainTypeSlave
+		createOpts.SOAEmail = ""
+		createOpts.MasterIPs = ipStrings(opts.MasterIPs)
+	}
+	domain, err := p.api.CreateDomain(ctx, createOpts)
+	if err != nil {
+		return fmt.Errorf("could not create domain: %w", err)
+	}
+	p.cacheDomainID(zone, domain.ID)
+	if domain.Type == linodego.DomainTypeSlave {
+		return nil
+	}
+	var records []libdns.Record
+	for _, preset := range opts.Presets {
+		records = append(records, preset.Records...)
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	if _, err := p.appendRecords(ctx, zone, records); err != nil {
+		return fmt.Errorf("zone was created, but could not add preset records: %w", err)
+	}
+	return nil
+}
+
+// ImportZone creates a Linode domain for the zone by transferring it from
+// remoteNameserver, which must allow zone transfers to Linode's nameservers.
+// The domain is a master, so its records can be managed through the provider
+// once imported.
+func (p *Provider) ImportZone(ctx context.Context, zone, remoteNameserver string) error {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.mutex.Unlock()
+	if err := p.checkWritable(zone); err != nil {
+		return err
+	}
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	ctx = withZone(ctx, zone)
+	body := map[string]string{
+		"domain":            strings.TrimSuffix(zone, "."),
+		"remote_nameserver": remoteNameserver,
+	}
+	var domain linodego.Domain
+	if err := p.apiPost(ctx, "domains/import", body, &domain); err != nil {
+		return fmt.Errorf("could not import domain: %w", err)
+	}
+	p.cacheDomainID(zone, domain.ID)
+	return nil
+}
+
+// CloneZone creates a Linode domain for newZone with a copy of the records and
+// settings of sourceZone.
+func (p *Provider) CloneZone(ctx context.Context, sourceZone, newZone string) error {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.mutex.Unlock()
+	if err := p.checkWritable(newZone); err != nil {
+		return err
+	}
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	sourceID, err := p.getDomainIDByZone(withZone(ctx, sourceZone), sourceZone)
+	if err != nil {
+		return fmt.Errorf("could not find domain ID for zone: %s: %w", sourceZone, err)
+	}
+	body := map[string]string{"domain": strings.TrimSuffix(newZone, ".")}
+	var domain linodego.Domain
+	if err := p.apiPost(withZone(ctx, newZone), fmt.Sprintf("domains/%d/clone", sourceID), body, &domain); err != nil {
+		return fmt.Errorf("could not clone domain: %w", err)
+	}
+	p.cacheDomainID(newZone, domain.ID)
+	return nil
+}
+
+// DeleteZone deletes the zone's Linode domain along with all of its records.
+// If Provider.Confirm is set, it is asked first with all records of the zone
+// as deletes; MaxDeletionsPerCall and MaxDeletionPercent do not apply.
+func (p *Provider) DeleteZone(ctx context.Context, zone string) error {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.mutex.Unlock()
+	if err := p.checkWritable(zone); err != nil {
+		return err
+	}
+	if err := p.init(ctx); err != nil {
+		return err
+	}
+	ctx = withZone(ctx, zone)
+	unlock, err := p.lockZone(ctx, zone)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	if p.Confirm != nil {
+		records, err := p.listDomainRecords(ctx, zone, domainID)
+		if err != nil {
+			return err
+		}
+		if err := p.confirm(ctx, DestructiveChanges{Zone: zone, Operation: "delete_zone", Deletes: records}); err != nil {
+			return err
+		}
+	}
+	if err := p.api.DeleteDomain(ctx, domainID); err != nil {
+		return fmt.Errorf("could not delete domain: %w", err)
+	}
+	p.uncacheDomainID(zone)
+	p.invalidateRecords(zone)
+	return nil
+}
+
+// ListZones lists the zones of all domains on the account.
+func (p *Provider) ListZones(ctx context.Context) ([]libdns.Zone, error) {
+	return p.ListZonesWithTags(ctx)
+}
+
+// ListZonesWithTags lists the zones of the domains on the account that have
+// all of the given tags.
+func (p *Provider) ListZonesWithTags(ctx context.Context, tags ...string) ([]libdns.Zone, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	listOptions := linodego.NewListOptions(0, "")
+	listOptions.PageSize = listPageSize
+	domains, err := p.api.ListDomains(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("could not list domains: %w", err)
+	}
+	zones := make([]libdns.Zone, 0, len(domains))
+	for _, domain := range domains {
+		if hasTags(domain.Tags, tags) {
+			zones = append(zones, libdns.Zone{Name: domain.Domain + "."})
+		}
+	}
+	return zones, nil
+}
+
+// GetZoneTags returns the tags of the zone's domain.
+func (p *Provider) GetZoneTags(ctx context.Context, zone string) ([]string, error) {
+	domain, err := p.zoneDomain(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Tags, nil
+}
+
+// SetZoneTags replaces the tags of the zone's domain.
+func (p *Provider) SetZoneTags(ctx context.Context, zone string, tags []string) error {
+	_, err := p.updateZoneDomain(ctx, zone, func(opts *linodego.DomainUpdateOptions) {
+		opts.Tags = append([]string{}, tags...)
+	})
+	return err
+}
+
+// ZoneWritable reports whether the user of the API token may change the
+// zone's domain and records, according to the user's profile and grants. It
+// only reads, so it can check access to production zones; the token's OAuth
+// scopes, which Linode reports only in the X-OAuth-Scopes response header,
+// are not checked.
+func (p *Provider) ZoneWritable(ctx context.Context, zone string) (bool, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return false, err
+	}
+	profileAPI, ok := p.api.(ProfileAPI)
+	if !ok {
+		return false, errors.New("the API does not report the permissions of the token")
+	}
+	ctx = withZone(ctx, zone)
+	profile, err := profileAPI.GetProfile(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not get profile: %w", err)
+	}
+	if !profile.Restricted {
+		return true, nil
+	}
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return false, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	grants, err := profileAPI.GrantsList(ctx)
+	if err != nil {
+		return false, fmt.Errorf("could not get grants: %w", err)
+	}
+	for _, grant := range grants.Domain {
+		if grant.ID == domainID {
+			return grant.Permissions == linodego.AccessLevelReadWrite, nil
+		}
+	}
+	return false, nil
+}
+
+// zoneDomain returns the Linode domain of the zone.
+func (p *Provider) zoneDomain(ctx context.Context, zone string) (*linodego.Domain, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	domain, err := p.api.GetDomain(ctx, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get domain: %w", err)
+	}
+	return domain, nil
+}
+
+// updateZoneDomain changes the settings of the zone's Linode domain with
+// update, see updateDomain.
+func (p *Provider) updateZoneDomain(ctx context.Context, zone string, update func(*linodego.DomainUpdateOptions)) (*linodego.Domain, error) {
+	ctx, err := p.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer p.mutex.Unlock()
+	if err := p.checkWritable(zone); err != nil {
+		return nil, err
+	}
+	if err := p.init(ctx); err != nil {
+		return nil, err
+	}
+	ctx = withZone(ctx, zone)
+	domainID, err := p.getDomainIDByZone(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("could not find domain ID for zone: %s: %w", zone, err)
+	}
+	return p.updateDomain(ctx, domainID, update)
+}
+
+// updateDomain changes the settings of a domain with update. The other
+// settings are sent unchanged, since the Linode API would otherwise clear the
+// domain's tags and IP address lists.
+func (p *Provider) updateDomain(ctx context.Context, domainID int, update func(*linodego.DomainUpdateOptions)) (*linodego.Domain, error) {
+	domain, err := p.api.GetDomain(ctx, domainID)
+	if err != nil {
+		return nil, fmt.Errorf("could not get domain: %w", err)
+	}
+	opts := domain.GetUpdateOptions()
+	update(&opts)
+	domain, err = p.api.UpdateDomain(ctx, domainID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not update domain: %w", err)
+	}
+	return domain, nil
+}
+
+// hasTags reports whether tags contains all of wanted.
+func hasTags(tags, wanted []string) bool {
+	for _, w := range wanted {
+		found := false
+		for _, tag := range tags {
+			if strings.EqualFold(tag, w) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package linode_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/libdns/linode"
+	"github.com/linode/linodego"
+)
+
+func TestCreateZoneWrapsAPIError(t *testing.T) {
+	provider, _, _ := newTestProvider(t)
+	err := provider.CreateZone(context.Background(), testZone, linode.CreateZoneOptions{SOAEmail: "admin@example.com"})
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusBadRequest {
+		t.Fatalf("CreateZone error = %v, want a 400 linodego.Error", err)
+	}
+}
+
+// failingGetDomainAPI fails every request for a single domain.
+type failingGetDomainAPI struct {
+	linode.DomainAPI
+}
+
+func (failingGetDomainAPI) GetDomain(context.Context, int) (*linodego.Domain, error) {
+	return nil, &linodego.Error{Code: http.StatusServiceUnavailable, Message: "unavailable"}
+}
+
+func TestDomainTTLWrapsAPIError(t *testing.T) {
+	provider, fake, _ := newTestProvider(t)
+	mustAppend(t, provider, mustParse(t, "www", "A", "192.0.2.1"))
+	provider = &linode.Provider{API: failingGetDomainAPI{DomainAPI: fake}, ResolveDefaultTTL: true}
+	_, err := provider.GetRecords(context.Background(), testZone)
+	var apiErr *linodego.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+		t.Fatalf("GetRecords error = %v, want a 503 linodego.Error", err)
+	}
+}
+
+func TestZoneWritable(t *testing.T) {
+	provider, fake, domainID := newTestProvider(t)
+	for _, test := range []struct {
+		name   string
+		grants *linodego.UserGrants
+		want   bool
+	}{
+		{"unrestricted", nil, true},
+		{"read_write", &linodego.UserGrants{Domain: []linodego.GrantedEntity{{ID: domainID, Permissions: linodego.AccessLevelReadWrite}}}, true},
+		{"read_only", &linodego.UserGrants{Domain: []linodego.GrantedEntity{{ID: domainID, Permissions: linodego.AccessLevelReadOnly}}}, false},
+		{"other domain", &linodego.UserGrants{Domain: []linodego.GrantedEntity{{ID: domainID + 1, Permissions: linodego.AccessLevelReadWrite}}}, false},
+	} {
+		fake.Grants = test.grants
+		writable, err := provider.ZoneWritable(context.Background(), testZone)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if writable != test.want {
+			t.Errorf("%s: ZoneWritable = %v, want %v", test.name, writable, test.want)
+		}
+	}
+}